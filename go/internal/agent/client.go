@@ -23,97 +23,184 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal"
 	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
 )
 
 // Client provides communication management with gse agent.
 type Client interface {
 	// Launch starts connecting to an agent and holding, wait until it's connected or the context is done.
+	// it could be called again after the client is terminated.
 	Launch(ctx context.Context) error
 
+	// Drain marks the client draining before Terminate, the connection is still held for in-flight works.
+	Drain() error
+
 	// Terminate terminates the connection holding from an agent.
+	// it waits for the in-flight sends until the context is done, then closes the connection.
+	// the sends still writing are aborted and reported in *types.TerminateError.
 	Terminate(ctx context.Context) error
 
+	// Authorize marks the connected client authorized by agent.
+	Authorize()
+
+	// State returns the lifecycle state of client.
+	State() types.ClientState
+
 	// IsConnected returns whether it's connected to an agent.
 	IsConnected() bool
 
 	// SendMessage sends a message respond to server though agent.
 	SendMessage(ctx context.Context, header IHeader, content []byte) error
+
+	// Reconnects returns the number of times the connection is re-established after lost.
+	Reconnects() uint64
 }
 
 // New creates a new client.
 func New(conf Config) Client {
 	return &client{
 		conf: conf,
-		done: make(chan struct{}),
 	}
 }
 
 type client struct {
 	conf Config
 
-	launched  atomic.Bool
-	connected atomic.Bool
+	state stateMachine
+
+	// conn is the current connection, connMutex only protects the reference,
+	// so that the connection could be closed while a send is blocked in writing.
+	conn      net.Conn
+	connMutex sync.Mutex
 
-	conn  net.Conn
-	mutex sync.Mutex
+	// writeMutex serializes the writes to connection.
+	writeMutex sync.Mutex
 
-	done chan struct{}
+	// sending counts the in-flight sends, closing stops admitting new sends once Terminate starts waiting.
+	// the state check and counting of a send are done under sendingMutex, so that Terminate never misses it.
+	sending      internal.InFlight
+	closing      bool
+	sendingMutex sync.Mutex
+
+	// reconnects counts the connections re-established after lost.
+	reconnects atomic.Uint64
+
+	// cancel stops holding connection of current launch, and stopped is closed when the holding exited.
+	cancel  context.CancelFunc
+	stopped chan struct{}
+	mutex   sync.Mutex
 }
 
 // Launch starts connecting to an agent and holding, wait until it's connected or the context is done.
 func (c *client) Launch(ctx context.Context) error {
-	if c.launched.Load() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.state.transit(types.ClientStateConnecting,
+		types.ClientStateIdle, types.ClientStateStopped); !ok {
 		return types.ErrAlreadyLaunched()
 	}
 
-	c.launched.Store(true)
+	holdCtx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	notifyConnectedOnce := make(chan struct{}, 1)
 
-	notifyConnectedOnce := make(chan struct{})
-	defer close(notifyConnectedOnce)
+	c.cancel = cancel
+	c.stopped = stopped
 
-	go c.holdConnection(notifyConnectedOnce)
+	c.sendingMutex.Lock()
+	c.closing = false
+	c.sendingMutex.Unlock()
 
-	for {
-		select {
-		case <-ctx.Done():
-			c.launched.Store(false)
-			c.done <- struct{}{}
+	go func() {
+		defer close(stopped)
+		c.holdConnection(holdCtx, notifyConnectedOnce)
+	}()
 
-			return types.ErrContextDone()
+	select {
+	case <-ctx.Done():
+		cancel()
+		<-stopped
+		c.state.transit(types.ClientStateStopped, types.ClientStateConnecting, types.ClientStateConnected)
 
-		case <-notifyConnectedOnce:
-			return nil
-		}
+		return types.ErrContextDone()
+
+	case <-notifyConnectedOnce:
+		return nil
 	}
 }
 
+// Drain marks the client draining before Terminate.
+func (c *client) Drain() error {
+	if _, ok := c.state.transit(types.ClientStateDraining,
+		types.ClientStateConnecting, types.ClientStateConnected, types.ClientStateAuthorized); !ok {
+		return types.ErrNotLaunched()
+	}
+
+	return nil
+}
+
 // Terminate terminates the connection holding from an agent.
-func (c *client) Terminate(_ context.Context) error {
-	if !c.launched.Load() {
+func (c *client) Terminate(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.state.transit(types.ClientStateDraining, types.ClientStateConnecting,
+		types.ClientStateConnected, types.ClientStateAuthorized, types.ClientStateDraining); !ok {
 		return types.ErrNotLaunched()
 	}
 
-	c.launched.Store(false)
-	c.done <- struct{}{}
+	c.sendingMutex.Lock()
+	c.closing = true
+	c.sendingMutex.Unlock()
+
+	abandoned := c.sending.Wait(ctx)
+
+	// closing the connection in holding also aborts the abandoned sends.
+	c.cancel()
+	<-c.stopped
+
+	c.state.transit(types.ClientStateStopped, types.ClientStateDraining)
+
+	if abandoned > 0 {
+		c.conf.Logger.Warn("terminated with %d sends abandoned", abandoned)
+		return &types.TerminateError{AbandonedSends: abandoned, Err: ctx.Err()}
+	}
 
 	return nil
 }
 
+// Authorize marks the connected client authorized by agent.
+func (c *client) Authorize() {
+	c.state.transit(types.ClientStateAuthorized, types.ClientStateConnected)
+}
+
+// State returns the lifecycle state of client.
+func (c *client) State() types.ClientState {
+	return c.state.get()
+}
+
 // IsConnected returns whether it's connected to an agent.
 func (c *client) IsConnected() bool {
-	return c.connected.Load()
+	c.connMutex.Lock()
+	defer c.connMutex.Unlock()
+
+	return c.conn != nil
+}
+
+// Reconnects returns the number of times the connection is re-established after lost.
+func (c *client) Reconnects() uint64 {
+	return c.reconnects.Load()
 }
 
 // SendMessage sends a message respond to server though agent.
 func (c *client) SendMessage(_ context.Context, header IHeader, content []byte) error {
-	if !c.launched.Load() {
+	if !c.admit() {
 		return types.ErrNotLaunched()
 	}
 
-	if !c.connected.Load() {
-		return types.NotConnected()
-	}
+	defer c.sending.Done()
 
 	headerBuf, err := header.EncodeBuffer()
 	if err != nil {
@@ -124,88 +211,114 @@ func (c *client) SendMessage(_ context.Context, header IHeader, content []byte)
 	copy(buffer, headerBuf)
 	copy(buffer[len(headerBuf):], content)
 
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
 
-	if c.conn == nil {
+	c.connMutex.Lock()
+	conn := c.conn
+	c.connMutex.Unlock()
+
+	if conn == nil {
 		return types.NotConnected()
 	}
 
-	if _, err := c.conn.Write(buffer); err != nil {
+	if _, err := conn.Write(buffer); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (c *client) holdConnection(notifyConnectedOnce chan<- struct{}) {
-	for {
-		select {
-		case <-c.done:
-			return
+// admit counts the send in flight if the client is launched and not terminating.
+func (c *client) admit() bool {
+	c.sendingMutex.Lock()
+	defer c.sendingMutex.Unlock()
 
-		default:
-			conn, err := c.Dial()
-			if err != nil {
-				c.conf.Logger.Warn("connect to socket failed: %v. retrying in %s", err, c.conf.ReconnectInterval.String())
+	if state := c.state.get(); c.closing || state == types.ClientStateIdle || state == types.ClientStateStopped {
+		return false
+	}
 
-				// interval before retry.
-				time.Sleep(c.conf.ReconnectInterval)
+	c.sending.Add()
 
-				continue
-			}
+	return true
+}
 
-			c.conf.Logger.Info("connected to socket: %s", conn.RemoteAddr().String())
+func (c *client) holdConnection(ctx context.Context, notifyConnectedOnce chan<- struct{}) {
+	for {
+		conn, err := c.Dial(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
 
-			c.connectionConnect(conn)
+			c.conf.Logger.Warn("connect to socket failed: %v. retrying in %s", err, c.conf.ReconnectInterval.String())
 
-			// notify connected once
-			if notifyConnectedOnce != nil {
-				notifyConnectedOnce <- struct{}{}
-				notifyConnectedOnce = nil
+			// interval before retry.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.conf.ReconnectInterval):
 			}
 
-			// brings up receive handler.
-			receiveErr := make(chan error)
-			go func() { receiveErr <- c.handleReceive(conn) }()
+			continue
+		}
 
-			select {
-			case <-c.done:
-				c.conf.Logger.Info("forcely disconnected from socket: %s", conn.RemoteAddr().String())
-				c.connectionDisconnect()
+		c.conf.Logger.Info("connected to socket: %s", conn.RemoteAddr().String())
 
-				return
+		c.connectionConnect(conn)
 
-			case <-receiveErr:
-				c.conf.Logger.Warn("lost connection from socket: %s", conn.RemoteAddr().String())
-				c.connectionDisconnect()
-			}
+		// notify connected once, the following connections are reconnections.
+		if notifyConnectedOnce != nil {
+			notifyConnectedOnce <- struct{}{}
+			notifyConnectedOnce = nil
+		} else {
+			c.reconnects.Add(1)
+		}
+
+		// brings up receive handler.
+		receiveErr := make(chan error, 1)
+		go func() { receiveErr <- c.handleReceive(conn) }()
+
+		select {
+		case <-ctx.Done():
+			c.conf.Logger.Info("forcely disconnected from socket: %s", conn.RemoteAddr().String())
+			c.connectionDisconnect()
+
+			return
+
+		case <-receiveErr:
+			c.conf.Logger.Warn("lost connection from socket: %s", conn.RemoteAddr().String())
+			c.connectionDisconnect()
 		}
 	}
 }
 
 func (c *client) connectionConnect(conn net.Conn) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	c.connMutex.Lock()
+	defer c.connMutex.Unlock()
 
 	if c.conn != nil {
 		_ = c.conn.Close()
 	}
 
 	c.conn = conn
-	c.connected.Store(true)
+	c.state.transit(types.ClientStateConnected, types.ClientStateConnecting)
 }
 
 func (c *client) connectionDisconnect() {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	c.connMutex.Lock()
 
 	if c.conn != nil {
 		_ = c.conn.Close()
 	}
 
 	c.conn = nil
-	c.connected.Store(false)
+	c.state.transit(types.ClientStateConnecting, types.ClientStateConnected, types.ClientStateAuthorized)
+	c.connMutex.Unlock()
+
+	if c.conf.DisconnectCallback != nil {
+		c.conf.DisconnectCallback()
+	}
 }
 
 func (c *client) handleReceive(conn net.Conn) error {
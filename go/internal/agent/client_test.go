@@ -0,0 +1,250 @@
+//go:build unix
+
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// fakeAgent accepts the connections on a domain socket and discards everything received.
+type fakeAgent struct {
+	path     string
+	listener net.Listener
+	conns    sync.WaitGroup
+}
+
+func newFakeAgent(t *testing.T) *fakeAgent {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "agent.sock")
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen on %s failed: %v", path, err)
+	}
+
+	a := &fakeAgent{path: path, listener: listener}
+
+	go a.serve()
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+		a.conns.Wait()
+	})
+
+	return a
+}
+
+func (a *fakeAgent) serve() {
+	for {
+		conn, err := a.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		a.conns.Add(1)
+		go func() {
+			defer a.conns.Done()
+			defer conn.Close()
+
+			_, _ = io.Copy(io.Discard, conn)
+		}()
+	}
+}
+
+func newTestClient(a *fakeAgent) Client {
+	return New(Config{
+		DomainSocketPath:    a.path,
+		ReconnectInterval:   10 * time.Millisecond,
+		MaxMessageSizeBytes: 1024,
+		RecvCallback:        func(IHeader, []byte) {},
+		RecvHeader:          NewDataDownHeader(),
+		Logger:              types.NewEmptyLogger(),
+	})
+}
+
+func TestClientRelaunch(t *testing.T) {
+	c := newTestClient(newFakeAgent(t))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := c.Launch(ctx); err != nil {
+			t.Fatalf("launch #%d failed: %v", i, err)
+		}
+
+		if state := c.State(); state != types.ClientStateConnected {
+			t.Fatalf("state is %s after launch #%d, want %s", state, i, types.ClientStateConnected)
+		}
+
+		if err := c.Launch(ctx); !errors.Is(err, types.ErrAlreadyLaunched()) {
+			t.Fatalf("launch again returns %v, want %v", err, types.ErrAlreadyLaunched())
+		}
+
+		if err := c.SendMessage(ctx, NewDataUpHeader(), []byte("data")); err != nil {
+			t.Fatalf("send after launch #%d failed: %v", i, err)
+		}
+
+		if err := c.Terminate(ctx); err != nil {
+			t.Fatalf("terminate #%d failed: %v", i, err)
+		}
+
+		if state := c.State(); state != types.ClientStateStopped {
+			t.Fatalf("state is %s after terminate #%d, want %s", state, i, types.ClientStateStopped)
+		}
+
+		if c.IsConnected() {
+			t.Fatalf("still connected after terminate #%d", i)
+		}
+
+		if err := c.SendMessage(ctx, NewDataUpHeader(), []byte("data")); !errors.Is(err, types.ErrNotLaunched()) {
+			t.Fatalf("send after terminate #%d returns %v, want %v", i, err, types.ErrNotLaunched())
+		}
+	}
+}
+
+func TestClientTerminateNotLaunched(t *testing.T) {
+	c := newTestClient(newFakeAgent(t))
+
+	if err := c.Terminate(context.Background()); !errors.Is(err, types.ErrNotLaunched()) {
+		t.Fatalf("terminate returns %v, want %v", err, types.ErrNotLaunched())
+	}
+}
+
+func TestClientConcurrentTerminate(t *testing.T) {
+	c := newTestClient(newFakeAgent(t))
+	ctx := context.Background()
+
+	if err := c.Launch(ctx); err != nil {
+		t.Fatalf("launch failed: %v", err)
+	}
+
+	const terminators = 8
+
+	var wg sync.WaitGroup
+	errs := make(chan error, terminators)
+
+	for i := 0; i < terminators; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.Terminate(ctx)
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, types.ErrNotLaunched()):
+			t.Fatalf("concurrent terminate returns %v", err)
+		}
+	}
+
+	if succeeded != 1 {
+		t.Fatalf("%d terminates succeeded, want 1", succeeded)
+	}
+
+	if state := c.State(); state != types.ClientStateStopped {
+		t.Fatalf("state is %s after terminate, want %s", state, types.ClientStateStopped)
+	}
+}
+
+func TestClientSendDuringTerminate(t *testing.T) {
+	c := newTestClient(newFakeAgent(t))
+	ctx := context.Background()
+
+	if err := c.Launch(ctx); err != nil {
+		t.Fatalf("launch failed: %v", err)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				err := c.SendMessage(ctx, NewDataUpHeader(), []byte("data"))
+				if err != nil && !errors.Is(err, types.ErrNotLaunched()) {
+					t.Errorf("send during terminate returns %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if err := c.Terminate(ctx); err != nil {
+		t.Errorf("terminate failed: %v", err)
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestClientNoGoroutineLeak(t *testing.T) {
+	a := newFakeAgent(t)
+	ctx := context.Background()
+
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 10; i++ {
+		c := newTestClient(a)
+
+		if err := c.Launch(ctx); err != nil {
+			t.Fatalf("launch #%d failed: %v", i, err)
+		}
+
+		if err := c.Terminate(ctx); err != nil {
+			t.Fatalf("terminate #%d failed: %v", i, err)
+		}
+	}
+
+	// the receiving goroutines exit asynchronously after the connections are closed.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("%d goroutines after launch and terminate, %d before", after, before)
+	}
+}
@@ -45,6 +45,9 @@ type Config struct {
 	// RecvHeader describes the header for agent message service to call when receive a message.
 	RecvHeader IHeader
 
+	// DisconnectCallback describes the callback function to call when the connection is lost or closed, it's optional.
+	DisconnectCallback func()
+
 	// Logger describes the logger for this service.
 	// default logger will prints to stdout.
 	Logger types.Logger
@@ -39,9 +39,12 @@ func NewDataUpHeader() *DataUpHeader {
 
 // DataUpHeader describes the data up header of protocol.
 type DataUpHeader struct {
-	ProtoType  uint32
-	DataID     uint32
-	UTCTime    uint32
+	ProtoType uint32
+	DataID    uint32
+
+	// UTCTime is the report time in unix seconds.
+	UTCTime uint32
+
 	BodyLength uint32
 	Reserved0  uint32
 	Reserved1  uint32
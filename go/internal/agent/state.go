@@ -0,0 +1,59 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agent
+
+import (
+	"sync"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// stateMachine holds the lifecycle state of client, all transitions are checked and serialized.
+//
+//	idle/stopped --Launch--> connecting <--lost-- connected/authorized
+//	connecting --dialed--> connected --keepalive--> authorized
+//	connecting/connected/authorized --Terminate--> draining --drained--> stopped
+//	connecting --Launch failed--> stopped
+type stateMachine struct {
+	state types.ClientState
+	mutex sync.RWMutex
+}
+
+// get returns the current state.
+func (m *stateMachine) get() types.ClientState {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.state
+}
+
+// transit changes the state to target if the current state is one of from,
+// returns the state before transition and whether it's changed.
+func (m *stateMachine) transit(target types.ClientState, from ...types.ClientState) (types.ClientState, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	current := m.state
+	for _, state := range from {
+		if current == state {
+			m.state = target
+
+			return current, true
+		}
+	}
+
+	return current, false
+}
@@ -0,0 +1,94 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agent
+
+import (
+	"testing"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+func TestStateMachineTransit(t *testing.T) {
+	tests := []struct {
+		name    string
+		current types.ClientState
+		target  types.ClientState
+		from    []types.ClientState
+		changed bool
+	}{
+		{
+			name:    "launch from idle",
+			current: types.ClientStateIdle,
+			target:  types.ClientStateConnecting,
+			from:    []types.ClientState{types.ClientStateIdle, types.ClientStateStopped},
+			changed: true,
+		},
+		{
+			name:    "relaunch from stopped",
+			current: types.ClientStateStopped,
+			target:  types.ClientStateConnecting,
+			from:    []types.ClientState{types.ClientStateIdle, types.ClientStateStopped},
+			changed: true,
+		},
+		{
+			name:    "launch while connected",
+			current: types.ClientStateConnected,
+			target:  types.ClientStateConnecting,
+			from:    []types.ClientState{types.ClientStateIdle, types.ClientStateStopped},
+			changed: false,
+		},
+		{
+			name:    "terminate while authorized",
+			current: types.ClientStateAuthorized,
+			target:  types.ClientStateDraining,
+			from:    []types.ClientState{types.ClientStateConnected, types.ClientStateAuthorized},
+			changed: true,
+		},
+		{
+			name:    "authorize while draining",
+			current: types.ClientStateDraining,
+			target:  types.ClientStateAuthorized,
+			from:    []types.ClientState{types.ClientStateConnected},
+			changed: false,
+		},
+		{
+			name:    "no from states",
+			current: types.ClientStateIdle,
+			target:  types.ClientStateConnecting,
+			changed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &stateMachine{state: tt.current}
+
+			before, changed := m.transit(tt.target, tt.from...)
+			if before != tt.current || changed != tt.changed {
+				t.Fatalf("transit returns (%s, %v), want (%s, %v)", before, changed, tt.current, tt.changed)
+			}
+
+			want := tt.current
+			if tt.changed {
+				want = tt.target
+			}
+
+			if got := m.get(); got != want {
+				t.Fatalf("state is %s after transit, want %s", got, want)
+			}
+		})
+	}
+}
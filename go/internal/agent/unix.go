@@ -17,9 +17,14 @@
 
 package agent
 
-import "net"
+import (
+	"context"
+	"net"
+)
 
 // Dial connect to domain socket on unix.
-func (c *client) Dial() (net.Conn, error) {
-	return net.DialUnix("unix", nil, &net.UnixAddr{Name: c.conf.DomainSocketPath, Net: "unix"})
+func (c *client) Dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{}
+
+	return dialer.DialContext(ctx, "unix", c.conf.DomainSocketPath)
 }
@@ -18,11 +18,14 @@
 package agent
 
 import (
+	"context"
 	"fmt"
 	"net"
 )
 
 // Dial connect to local port on windows.
-func (c *client) Dial() (net.Conn, error) {
-	return net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", c.conf.LocalSocketPort))
+func (c *client) Dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{}
+
+	return dialer.DialContext(ctx, "tcp", fmt.Sprintf("127.0.0.1:%d", c.conf.LocalSocketPort))
 }
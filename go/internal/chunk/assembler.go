@@ -0,0 +1,244 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package chunk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// Assembler reassembles chunk frames into whole payloads.
+// partial transfers which not completed in timeout will be dropped, and the oldest partial transfers
+// will be evicted when the number or the total received bytes of partial transfers is over the limits.
+type Assembler struct {
+	timeout         time.Duration
+	maxBytes        uint64
+	maxPending      int
+	maxPendingBytes uint64
+
+	transfers map[string]*transfer
+	bytes     uint64
+	seq       uint64
+	mutex     sync.Mutex
+}
+
+type transfer struct {
+	header   Header
+	chunks   map[uint32][]byte
+	received uint32
+	bytes    uint64
+	deadline time.Time
+
+	// seq is the order of transfer in assembler, the smallest one is the oldest.
+	seq uint64
+}
+
+// NewAssembler creates a new Assembler.
+// maxBytes limits the size of a single reassembled payload, maxPending and maxPendingBytes limit the number
+// and the total received bytes of partial transfers, maxPendingBytes should not be less than maxBytes.
+func NewAssembler(timeout time.Duration, maxBytes uint64, maxPending int, maxPendingBytes uint64) *Assembler {
+	return &Assembler{
+		timeout:         timeout,
+		maxBytes:        maxBytes,
+		maxPending:      maxPending,
+		maxPendingBytes: max(maxPendingBytes, maxBytes),
+		transfers:       make(map[string]*transfer),
+	}
+}
+
+// Add adds a chunk frame from source into the assembler.
+// It returns the header and the whole payload once all chunks of the transfer arrived,
+// otherwise returns types.ErrChunkIncomplete.
+func (a *Assembler) Add(source string, frame []byte) (*Header, []byte, error) {
+	header, data, err := Decode(frame)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if header.Size > a.maxBytes {
+		return nil, nil, errors.Join(types.ErrInvalidChunk(),
+			fmt.Errorf("chunked message size %d over limit %d", header.Size, a.maxBytes))
+	}
+
+	// every chunk carries at least one byte, so the total is bounded by the size and the limit.
+	if header.Total == 0 || uint64(header.Total) > header.Size || uint64(header.Total) > a.maxBytes {
+		return nil, nil, errors.Join(types.ErrInvalidChunk(),
+			fmt.Errorf("invalid chunk total %d of size %d", header.Total, header.Size))
+	}
+
+	now := time.Now()
+	key := source + "/" + header.TransferID
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.expire(now)
+
+	t, ok := a.transfers[key]
+	if !ok {
+		for len(a.transfers) >= a.maxPending {
+			if !a.evictOldest(key) {
+				break
+			}
+		}
+
+		a.seq++
+		t = &transfer{
+			header:   *header,
+			chunks:   make(map[uint32][]byte),
+			deadline: now.Add(a.timeout),
+			seq:      a.seq,
+		}
+		a.transfers[key] = t
+	}
+
+	if t.header.Total != header.Total || header.Index >= t.header.Total ||
+		t.header.Size != header.Size || t.header.Checksum != header.Checksum {
+		a.remove(key)
+
+		return nil, nil, errors.Join(types.ErrInvalidChunk(),
+			fmt.Errorf("chunk header mismatch in transfer %s", header.TransferID))
+	}
+
+	// duplicated chunk, just ignore it.
+	if _, ok := t.chunks[header.Index]; ok {
+		return nil, nil, types.ErrChunkIncomplete()
+	}
+
+	if t.bytes+uint64(len(data)) > t.header.Size {
+		a.remove(key)
+
+		return nil, nil, errors.Join(types.ErrInvalidChunk(),
+			fmt.Errorf("chunks size over declared size %d in transfer %s", t.header.Size, header.TransferID))
+	}
+
+	// the declared size is under maxBytes, so it always fits after the other transfers are evicted.
+	for a.bytes+uint64(len(data)) > a.maxPendingBytes {
+		if !a.evictOldest(key) {
+			break
+		}
+	}
+
+	chunk := make([]byte, len(data))
+	copy(chunk, data)
+
+	t.chunks[header.Index] = chunk
+	t.received++
+	t.bytes += uint64(len(data))
+	a.bytes += uint64(len(data))
+
+	if t.received < t.header.Total {
+		return nil, nil, types.ErrChunkIncomplete()
+	}
+
+	a.remove(key)
+
+	payload, err := t.join()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &t.header, payload, nil
+}
+
+// Expire drops the partial transfers whose deadline passed, returns the number of dropped transfers.
+func (a *Assembler) Expire(now time.Time) int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.expire(now)
+}
+
+// Pending returns the number of partial transfers.
+func (a *Assembler) Pending() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return len(a.transfers)
+}
+
+// PendingBytes returns the total received bytes of partial transfers.
+func (a *Assembler) PendingBytes() uint64 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.bytes
+}
+
+func (a *Assembler) expire(now time.Time) int {
+	dropped := 0
+
+	for key, t := range a.transfers {
+		if now.After(t.deadline) {
+			a.remove(key)
+			dropped++
+		}
+	}
+
+	return dropped
+}
+
+// evictOldest drops the oldest partial transfer except the one in key, returns false if there is none.
+func (a *Assembler) evictOldest(key string) bool {
+	oldest := ""
+
+	for k, t := range a.transfers {
+		if k != key && (oldest == "" || t.seq < a.transfers[oldest].seq) {
+			oldest = k
+		}
+	}
+
+	if oldest == "" {
+		return false
+	}
+
+	a.remove(oldest)
+
+	return true
+}
+
+func (a *Assembler) remove(key string) {
+	if t, ok := a.transfers[key]; ok {
+		a.bytes -= t.bytes
+		delete(a.transfers, key)
+	}
+}
+
+func (t *transfer) join() ([]byte, error) {
+	if t.bytes != t.header.Size {
+		return nil, errors.Join(types.ErrInvalidChunk(),
+			fmt.Errorf("chunks size %d not match declared size %d", t.bytes, t.header.Size))
+	}
+
+	payload := make([]byte, 0, t.bytes)
+	for i := uint32(0); i < t.header.Total; i++ {
+		payload = append(payload, t.chunks[i]...)
+	}
+
+	sum := sha256.Sum256(payload)
+	if hex.EncodeToString(sum[:]) != t.header.Checksum {
+		return nil, errors.Join(types.ErrInvalidChunk(),
+			fmt.Errorf("checksum mismatch in transfer %s", t.header.TransferID))
+	}
+
+	return payload, nil
+}
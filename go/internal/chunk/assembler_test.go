@@ -0,0 +1,260 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package chunk
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+func TestAssemblerReassemble(t *testing.T) {
+	payload := bytes.Repeat([]byte("0123456789"), 10)
+
+	frames, err := Split("msg", payload, 7)
+	if err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		order []int
+	}{
+		{name: "in order", order: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14}},
+		{name: "reversed", order: []int{14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0}},
+		{name: "duplicated", order: []int{0, 0, 1, 2, 3, 4, 5, 6, 7, 7, 8, 9, 10, 11, 12, 13, 14}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAssembler(time.Minute, 1024, 16, 4096)
+
+			for i, index := range tt.order {
+				header, got, err := a.Add("agent", frames[index])
+				if i < len(tt.order)-1 {
+					if !errors.Is(err, types.ErrChunkIncomplete()) {
+						t.Fatalf("add frame %d returns %v, want %v", index, err, types.ErrChunkIncomplete())
+					}
+
+					continue
+				}
+
+				if err != nil {
+					t.Fatalf("add last frame failed: %v", err)
+				}
+
+				if header.MessageID != "msg" || !bytes.Equal(got, payload) {
+					t.Fatalf("reassembled message %s differs from payload", header.MessageID)
+				}
+			}
+
+			if pending := a.Pending(); pending != 0 {
+				t.Fatalf("%d transfers pending after reassembled", pending)
+			}
+		})
+	}
+}
+
+func TestAssemblerSources(t *testing.T) {
+	frames, err := Split("msg", []byte("payload"), 4)
+	if err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+
+	a := NewAssembler(time.Minute, 1024, 16, 4096)
+
+	// the same transfer from different sources are reassembled separately.
+	if _, _, err := a.Add("agent-1", frames[0]); !errors.Is(err, types.ErrChunkIncomplete()) {
+		t.Fatalf("add frame returns %v, want %v", err, types.ErrChunkIncomplete())
+	}
+
+	if _, _, err := a.Add("agent-2", frames[1]); !errors.Is(err, types.ErrChunkIncomplete()) {
+		t.Fatalf("add frame returns %v, want %v", err, types.ErrChunkIncomplete())
+	}
+
+	if pending := a.Pending(); pending != 2 {
+		t.Fatalf("%d transfers pending, want 2", pending)
+	}
+}
+
+func TestAssemblerEvict(t *testing.T) {
+	frames, err := Split("msg", []byte("payload!"), 4)
+	if err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		maxPending      int
+		maxPendingBytes uint64
+
+		// pending is the number of partial transfers left after the newest one is completed.
+		pending int
+	}{
+		{name: "pending transfers over limit", maxPending: 2, maxPendingBytes: 1024, pending: 1},
+		{name: "pending bytes over limit", maxPending: 16, maxPendingBytes: 8, pending: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAssembler(time.Minute, 8, tt.maxPending, tt.maxPendingBytes)
+
+			for _, source := range []string{"agent-1", "agent-2", "agent-3"} {
+				if _, _, err := a.Add(source, frames[0]); !errors.Is(err, types.ErrChunkIncomplete()) {
+					t.Fatalf("add frame returns %v, want %v", err, types.ErrChunkIncomplete())
+				}
+			}
+
+			// the oldest transfer is evicted.
+			if pending, bytes := a.Pending(), a.PendingBytes(); pending != 2 || bytes != 8 {
+				t.Fatalf("%d transfers of %d bytes pending, want 2 of 8 bytes", pending, bytes)
+			}
+
+			if _, _, err := a.Add("agent-1", frames[1]); !errors.Is(err, types.ErrChunkIncomplete()) {
+				t.Fatalf("add frame of evicted transfer returns %v, want %v", err, types.ErrChunkIncomplete())
+			}
+
+			// the newest transfer is still completed, and its bytes are released.
+			_, payload, err := a.Add("agent-3", frames[1])
+			if err != nil || string(payload) != "payload!" {
+				t.Fatalf("add last frame returns %q, %v", payload, err)
+			}
+
+			if pending, bytes := a.Pending(), a.PendingBytes(); pending != tt.pending || bytes != uint64(4*tt.pending) {
+				t.Fatalf("%d transfers of %d bytes pending, want %d", pending, bytes, tt.pending)
+			}
+		})
+	}
+}
+
+func TestAssemblerExpire(t *testing.T) {
+	frames, err := Split("msg", []byte("payload"), 4)
+	if err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+
+	a := NewAssembler(time.Minute, 1024, 16, 4096)
+
+	if _, _, err := a.Add("agent", frames[0]); !errors.Is(err, types.ErrChunkIncomplete()) {
+		t.Fatalf("add frame returns %v, want %v", err, types.ErrChunkIncomplete())
+	}
+
+	if dropped := a.Expire(time.Now()); dropped != 0 {
+		t.Fatalf("%d transfers dropped before deadline", dropped)
+	}
+
+	if dropped := a.Expire(time.Now().Add(2 * time.Minute)); dropped != 1 {
+		t.Fatalf("%d transfers dropped after deadline, want 1", dropped)
+	}
+}
+
+// forge re-encodes the frame with the header modified.
+func forge(t *testing.T, frame []byte, modify func(*Header)) []byte {
+	t.Helper()
+
+	header, data, err := Decode(frame)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	modify(header)
+
+	forged, err := Encode(header, data)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	return forged
+}
+
+func TestAssemblerRejectInvalid(t *testing.T) {
+	frames, err := Split("msg", []byte("payload"), 4)
+	if err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		frames [][]byte
+	}{
+		{
+			name:   "size over limit",
+			frames: [][]byte{forge(t, frames[0], func(h *Header) { h.Size = 4096 })},
+		},
+		{
+			name:   "total over size",
+			frames: [][]byte{forge(t, frames[0], func(h *Header) { h.Total = 100 })},
+		},
+		{
+			name:   "huge total",
+			frames: [][]byte{forge(t, frames[0], func(h *Header) { h.Total, h.Index = 1<<32-1, 0 })},
+		},
+		{
+			name: "total mismatch",
+			frames: [][]byte{frames[0], forge(t, frames[1], func(h *Header) {
+				h.Total = 3
+			})},
+		},
+		{
+			name: "checksum mismatch",
+			frames: [][]byte{frames[0], forge(t, frames[1], func(h *Header) {
+				h.Checksum = "forged"
+			})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAssembler(time.Minute, 1024, 16, 4096)
+
+			var err error
+			for _, frame := range tt.frames {
+				_, _, err = a.Add("agent", frame)
+			}
+
+			if !errors.Is(err, types.ErrInvalidChunk()) {
+				t.Fatalf("add returns %v, want %v", err, types.ErrInvalidChunk())
+			}
+
+			if pending := a.Pending(); pending != 0 {
+				t.Fatalf("%d transfers pending after invalid chunk", pending)
+			}
+		})
+	}
+}
+
+func TestAssemblerChecksumMismatch(t *testing.T) {
+	frames, err := Split("msg", []byte("payload"), 4)
+	if err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+
+	// all chunks carry the same forged checksum, the mismatch is found after joined.
+	a := NewAssembler(time.Minute, 1024, 16, 4096)
+	for i, frame := range frames {
+		_, _, err = a.Add("agent", forge(t, frame, func(h *Header) { h.Checksum = "forged" }))
+		if i < len(frames)-1 && !errors.Is(err, types.ErrChunkIncomplete()) {
+			t.Fatalf("add frame %d returns %v, want %v", i, err, types.ErrChunkIncomplete())
+		}
+	}
+
+	if !errors.Is(err, types.ErrInvalidChunk()) {
+		t.Fatalf("add last frame returns %v, want %v", err, types.ErrInvalidChunk())
+	}
+}
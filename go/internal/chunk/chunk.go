@@ -0,0 +1,179 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+// Package chunk provides splitting and reassembling of the messages larger than a single frame.
+package chunk
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// magic is the prefix of every chunk frame, followed by a json header line and the base64 encoded chunk data.
+// the frames are carried in the json string fields of GSE protocol, which only keep the valid utf-8, so the
+// raw chunk data which may split a multi-byte character or be binary is always encoded.
+const magic = "GSECHUNK/1\n"
+
+// Header describes the header of a chunk frame.
+type Header struct {
+	// TransferID identifies all chunks of one payload.
+	TransferID string `json:"transfer_id"`
+
+	// MessageID is the message id of the whole payload.
+	MessageID string `json:"message_id"`
+
+	// Index is the index of this chunk, starts from 0.
+	Index uint32 `json:"index"`
+
+	// Total is the number of chunks of the whole payload.
+	Total uint32 `json:"total"`
+
+	// Size is the size in bytes of the whole payload.
+	Size uint64 `json:"size"`
+
+	// Checksum is the hex encoded sha256 of the whole payload.
+	Checksum string `json:"checksum"`
+
+	// CRC is the crc32(IEEE) of this chunk data.
+	CRC uint32 `json:"crc"`
+}
+
+// Split splits the payload into chunk frames, each frame carries at most chunkSize bytes of payload.
+func Split(messageID string, payload []byte, chunkSize int) ([][]byte, error) {
+	if chunkSize <= 0 {
+		return nil, errors.Join(types.ErrInvalidChunk(), fmt.Errorf("invalid chunk size %d", chunkSize))
+	}
+
+	transferID, err := newTransferID()
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(payload)
+	total := (len(payload) + chunkSize - 1) / chunkSize
+
+	frames := make([][]byte, 0, total)
+	for i := 0; i < total; i++ {
+		data := payload[i*chunkSize : min((i+1)*chunkSize, len(payload))]
+
+		frame, err := Encode(&Header{
+			TransferID: transferID,
+			MessageID:  messageID,
+			Index:      uint32(i),
+			Total:      uint32(total),
+			Size:       uint64(len(payload)),
+			Checksum:   hex.EncodeToString(sum[:]),
+			CRC:        crc32.ChecksumIEEE(data),
+		}, data)
+		if err != nil {
+			return nil, err
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+// MessageID returns the message id of the chunk, it's unique for every chunk in one transfer.
+func MessageID(messageID string, index, total uint32) string {
+	return fmt.Sprintf("%s#chunk-%d/%d", messageID, index+1, total)
+}
+
+// IsFrame returns whether the data is a chunk frame.
+func IsFrame(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(magic))
+}
+
+// EncodedLen returns the size of the encoded chunk data of n bytes in frame, the header is not included.
+func EncodedLen(n int) int {
+	return base64.StdEncoding.EncodedLen(n)
+}
+
+// Encode encodes the chunk header and data into a frame.
+func Encode(header *Header, data []byte) ([]byte, error) {
+	info, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := make([]byte, len(magic)+len(info)+1+EncodedLen(len(data)))
+	n := copy(frame, magic)
+	n += copy(frame[n:], info)
+	frame[n] = '\n'
+	base64.StdEncoding.Encode(frame[n+1:], data)
+
+	return frame, nil
+}
+
+// Decode decodes the frame into chunk header and data.
+func Decode(frame []byte) (*Header, []byte, error) {
+	if !IsFrame(frame) {
+		return nil, nil, errors.Join(types.ErrInvalidChunk(), errors.New("missing chunk magic"))
+	}
+
+	rest := frame[len(magic):]
+
+	pos := bytes.IndexByte(rest, '\n')
+	if pos < 0 {
+		return nil, nil, errors.Join(types.ErrInvalidChunk(), errors.New("missing chunk header"))
+	}
+
+	header := new(Header)
+	if err := json.Unmarshal(rest[:pos], header); err != nil {
+		return nil, nil, errors.Join(types.ErrInvalidChunk(), err)
+	}
+
+	if header.TransferID == "" || header.Total == 0 || header.Index >= header.Total {
+		return nil, nil, errors.Join(types.ErrInvalidChunk(),
+			fmt.Errorf("invalid chunk header, transfer: %s, index: %d, total: %d",
+				header.TransferID, header.Index, header.Total))
+	}
+
+	data := make([]byte, base64.StdEncoding.DecodedLen(len(rest)-pos-1))
+
+	n, err := base64.StdEncoding.Decode(data, rest[pos+1:])
+	if err != nil {
+		return nil, nil, errors.Join(types.ErrInvalidChunk(), err)
+	}
+
+	data = data[:n]
+	if crc32.ChecksumIEEE(data) != header.CRC {
+		return nil, nil, errors.Join(types.ErrInvalidChunk(),
+			fmt.Errorf("chunk crc mismatch, transfer: %s, index: %d", header.TransferID, header.Index))
+	}
+
+	return header, data, nil
+}
+
+const transferIDLength = 8
+
+func newTransferID() (string, error) {
+	buf := make([]byte, transferIDLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,180 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package chunk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		chunkSize int
+		frames    int
+	}{
+		{name: "single chunk", size: 10, chunkSize: 10, frames: 1},
+		{name: "exact chunks", size: 30, chunkSize: 10, frames: 3},
+		{name: "last partial chunk", size: 31, chunkSize: 10, frames: 4},
+		{name: "one byte chunks", size: 5, chunkSize: 1, frames: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := bytes.Repeat([]byte("x"), tt.size)
+
+			frames, err := Split("msg", payload, tt.chunkSize)
+			if err != nil {
+				t.Fatalf("split failed: %v", err)
+			}
+
+			if len(frames) != tt.frames {
+				t.Fatalf("split into %d frames, want %d", len(frames), tt.frames)
+			}
+
+			var transferID string
+			joined := make([]byte, 0, tt.size)
+
+			for i, frame := range frames {
+				if !IsFrame(frame) {
+					t.Fatalf("frame %d is not a chunk frame", i)
+				}
+
+				header, data, err := Decode(frame)
+				if err != nil {
+					t.Fatalf("decode frame %d failed: %v", i, err)
+				}
+
+				if i == 0 {
+					transferID = header.TransferID
+				}
+
+				if header.TransferID != transferID || header.MessageID != "msg" || header.Index != uint32(i) ||
+					header.Total != uint32(tt.frames) || header.Size != uint64(tt.size) {
+					t.Fatalf("unexpected header of frame %d: %+v", i, header)
+				}
+
+				if len(data) > tt.chunkSize {
+					t.Fatalf("frame %d carries %d bytes over chunk size %d", i, len(data), tt.chunkSize)
+				}
+
+				joined = append(joined, data...)
+			}
+
+			if !bytes.Equal(joined, payload) {
+				t.Fatal("joined chunks differ from payload")
+			}
+		})
+	}
+}
+
+func TestSplitJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+	}{
+		{name: "multi-byte characters", payload: []byte("蓝鲸智云管控平台, 分片传输")},
+		{name: "binary", payload: []byte{0xff, 0x00, 0xfe, 0x80, 0xc3, 0x28, 0x0a, 0xe4, 0xbd, 0x00, 0xff}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, err := Split("msg", tt.payload, 5)
+			if err != nil {
+				t.Fatalf("split failed: %v", err)
+			}
+
+			a := NewAssembler(time.Minute, 1024, 16, 4096)
+
+			for i, frame := range frames {
+				// the frames are carried in the json string fields of GSE protocol.
+				body, err := json.Marshal(struct{ Content string }{Content: string(frame)})
+				if err != nil {
+					t.Fatalf("marshal frame %d failed: %v", i, err)
+				}
+
+				message := struct{ Content string }{}
+				if err := json.Unmarshal(body, &message); err != nil {
+					t.Fatalf("unmarshal frame %d failed: %v", i, err)
+				}
+
+				_, payload, err := a.Add("agent", []byte(message.Content))
+				if i < len(frames)-1 {
+					if !errors.Is(err, types.ErrChunkIncomplete()) {
+						t.Fatalf("add frame %d returns %v, want %v", i, err, types.ErrChunkIncomplete())
+					}
+
+					continue
+				}
+
+				if err != nil {
+					t.Fatalf("add last frame failed: %v", err)
+				}
+
+				if !bytes.Equal(payload, tt.payload) {
+					t.Fatalf("reassembled payload %q, want %q", payload, tt.payload)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitInvalidChunkSize(t *testing.T) {
+	if _, err := Split("msg", []byte("payload"), 0); !errors.Is(err, types.ErrInvalidChunk()) {
+		t.Fatalf("split with chunk size 0 returns %v, want %v", err, types.ErrInvalidChunk())
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	valid, err := Encode(&Header{TransferID: "t", Index: 0, Total: 1, Size: 4, CRC: 0}, []byte("data"))
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		frame []byte
+	}{
+		{name: "missing magic", frame: []byte("data")},
+		{name: "missing header", frame: []byte(magic + "{}")},
+		{name: "invalid header json", frame: []byte(magic + "{\n")},
+		{name: "empty transfer id", frame: []byte(magic + `{"index":0,"total":1}` + "\n")},
+		{name: "index over total", frame: []byte(magic + `{"transfer_id":"t","index":1,"total":1}` + "\n")},
+		{name: "invalid data", frame: []byte(magic + `{"transfer_id":"t","index":0,"total":1}` + "\n%%")},
+		{name: "crc mismatch", frame: valid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Decode(tt.frame); !errors.Is(err, types.ErrInvalidChunk()) {
+				t.Fatalf("decode returns %v, want %v", err, types.ErrInvalidChunk())
+			}
+		})
+	}
+}
+
+func TestMessageID(t *testing.T) {
+	if id := MessageID("msg", 0, 3); !strings.HasPrefix(id, "msg#") || id == MessageID("msg", 1, 3) {
+		t.Fatalf("chunk message id %s is not unique in transfer", id)
+	}
+}
@@ -0,0 +1,273 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+// Package envelope provides signing and encryption of the message content between server and plugin.
+package envelope
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// magic is the prefix of every sealed message, followed by the json encoded envelope.
+const magic = "GSEENV/1\n"
+
+const (
+	defaultReplayWindow = 5 * time.Minute
+
+	nonceLength = 12
+)
+
+// sealed describes the envelope of a message.
+type sealed struct {
+	KeyID     string `json:"kid"`
+	Timestamp int64  `json:"ts"`
+	Nonce     string `json:"nonce"`
+	Encrypted bool   `json:"enc"`
+	Payload   string `json:"payload"`
+	Signature string `json:"sig"`
+}
+
+// Envelope seals and opens the message content.
+type Envelope struct {
+	keyID        string
+	keys         map[string]types.EnvelopeKey
+	encrypt      bool
+	replayWindow time.Duration
+
+	// seen records the nonces of opened messages until they are out of replay window,
+	// expiries queues the same nonces in the order of expiring, so that the expired ones are removed from its head.
+	seen     map[string]struct{}
+	expiries []seenNonce
+	mutex    sync.Mutex
+}
+
+// seenNonce describes a nonce in seen and when it expires.
+type seenNonce struct {
+	nonce    string
+	expireAt time.Time
+}
+
+// New creates a new Envelope.
+func New(conf types.EnvelopeConfig) (*Envelope, error) {
+	e := &Envelope{
+		keyID:        conf.KeyID,
+		keys:         make(map[string]types.EnvelopeKey, len(conf.Keys)),
+		encrypt:      conf.Encrypt,
+		replayWindow: conf.ReplayWindow,
+		seen:         make(map[string]struct{}),
+	}
+
+	if e.replayWindow <= 0 {
+		e.replayWindow = defaultReplayWindow
+	}
+
+	for _, key := range conf.Keys {
+		if key.ID == "" || len(key.SignKey) == 0 {
+			return nil, errors.Join(types.ErrInvalidConfig(), errors.New("envelope key id or sign key is empty"))
+		}
+
+		if len(key.EncryptKey) != 0 {
+			if _, err := aes.NewCipher(key.EncryptKey); err != nil {
+				return nil, errors.Join(types.ErrInvalidConfig(), fmt.Errorf("envelope key %s: %w", key.ID, err))
+			}
+		}
+
+		e.keys[key.ID] = key
+	}
+
+	active, ok := e.keys[conf.KeyID]
+	if !ok {
+		return nil, errors.Join(types.ErrInvalidConfig(), fmt.Errorf("envelope key %s not found", conf.KeyID))
+	}
+
+	if conf.Encrypt && len(active.EncryptKey) == 0 {
+		return nil, errors.Join(types.ErrInvalidConfig(),
+			fmt.Errorf("envelope key %s has no encrypt key", conf.KeyID))
+	}
+
+	return e, nil
+}
+
+// Seal signs and optionally encrypts the content which belongs to the messageID.
+func (e *Envelope) Seal(messageID string, content []byte) ([]byte, error) {
+	key := e.keys[e.keyID]
+
+	nonce := make([]byte, nonceLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	payload := content
+	if e.encrypt {
+		gcm, err := newGCM(key.EncryptKey)
+		if err != nil {
+			return nil, err
+		}
+
+		payload = gcm.Seal(nil, nonce, content, []byte(messageID))
+	}
+
+	env := &sealed{
+		KeyID:     key.ID,
+		Timestamp: time.Now().UnixMilli(),
+		Nonce:     base64.StdEncoding.EncodeToString(nonce),
+		Encrypted: e.encrypt,
+		Payload:   base64.StdEncoding.EncodeToString(payload),
+	}
+	env.Signature = base64.StdEncoding.EncodeToString(sign(key.SignKey, messageID, env))
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(magic), data...), nil
+}
+
+// Open verifies and decrypts the sealed data which belongs to the messageID, returns the content.
+// all failures are joined with types.ErrEnvelopeRejected.
+func (e *Envelope) Open(messageID string, data []byte) ([]byte, error) {
+	content, err := e.open(messageID, data)
+	if err != nil {
+		return nil, errors.Join(types.ErrEnvelopeRejected(), err)
+	}
+
+	return content, nil
+}
+
+func (e *Envelope) open(messageID string, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(magic)) {
+		return nil, errors.New("message is not sealed")
+	}
+
+	env := new(sealed)
+	if err := json.Unmarshal(data[len(magic):], env); err != nil {
+		return nil, err
+	}
+
+	key, ok := e.keys[env.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown envelope key %s", env.KeyID)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(env.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hmac.Equal(signature, sign(key.SignKey, messageID, env)) {
+		return nil, errors.New("signature mismatch")
+	}
+
+	now := time.Now()
+	sealedAt := time.UnixMilli(env.Timestamp)
+	if sealedAt.Before(now.Add(-e.replayWindow)) || sealedAt.After(now.Add(e.replayWindow)) {
+		return nil, fmt.Errorf("sealed time %s out of replay window %s", sealedAt.String(), e.replayWindow.String())
+	}
+
+	if !e.remember(env.KeyID+"/"+env.Nonce, now) {
+		return nil, errors.New("replayed message")
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if !env.Encrypted {
+		return payload, nil
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(env.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key.EncryptKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != gcm.NonceSize() {
+		return nil, errors.New("invalid nonce size")
+	}
+
+	return gcm.Open(nil, nonce, payload, []byte(messageID))
+}
+
+// remember records the nonce, returns false if it's already seen in replay window.
+func (e *Envelope) remember(nonce string, now time.Time) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	// every nonce is kept for the same duration, so they expire in the order of remembering.
+	expired := 0
+	for expired < len(e.expiries) && now.After(e.expiries[expired].expireAt) {
+		delete(e.seen, e.expiries[expired].nonce)
+		expired++
+	}
+
+	e.expiries = e.expiries[expired:]
+
+	if _, ok := e.seen[nonce]; ok {
+		return false
+	}
+
+	// sealed time is at most one window ahead of now, keep the nonce until it could not pass the time check.
+	e.seen[nonce] = struct{}{}
+	e.expiries = append(e.expiries, seenNonce{nonce: nonce, expireAt: now.Add(2 * e.replayWindow)}) // nolint:mnd
+
+	return true
+}
+
+func sign(signKey []byte, messageID string, env *sealed) []byte {
+	mac := hmac.New(sha256.New, signKey)
+	for _, field := range []string{
+		magic, env.KeyID, strconv.FormatInt(env.Timestamp, 10), env.Nonce,
+		strconv.FormatBool(env.Encrypted), messageID, env.Payload,
+	} {
+		mac.Write([]byte(field))
+		mac.Write([]byte{0})
+	}
+
+	return mac.Sum(nil)
+}
+
+func newGCM(encryptKey []byte) (cipher.AEAD, error) {
+	if len(encryptKey) == 0 {
+		return nil, errors.New("encrypt key is empty")
+	}
+
+	block, err := aes.NewCipher(encryptKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
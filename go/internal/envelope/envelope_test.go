@@ -0,0 +1,242 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package envelope
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+var (
+	oldKey = types.EnvelopeKey{ID: "k1", SignKey: []byte("sign-1"), EncryptKey: bytes.Repeat([]byte("1"), 16)}
+	newKey = types.EnvelopeKey{ID: "k2", SignKey: []byte("sign-2"), EncryptKey: bytes.Repeat([]byte("2"), 32)}
+)
+
+func newEnvelope(t *testing.T, keyID string, encrypt bool) *Envelope {
+	t.Helper()
+
+	e, err := New(types.EnvelopeConfig{
+		KeyID:   keyID,
+		Keys:    []types.EnvelopeKey{oldKey, newKey},
+		Encrypt: encrypt,
+	})
+	if err != nil {
+		t.Fatalf("create envelope failed: %v", err)
+	}
+
+	return e
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		conf types.EnvelopeConfig
+	}{
+		{name: "key not found", conf: types.EnvelopeConfig{KeyID: "k3", Keys: []types.EnvelopeKey{oldKey}}},
+		{name: "empty sign key", conf: types.EnvelopeConfig{KeyID: "k1", Keys: []types.EnvelopeKey{{ID: "k1"}}}},
+		{
+			name: "invalid encrypt key",
+			conf: types.EnvelopeConfig{KeyID: "k1", Keys: []types.EnvelopeKey{
+				{ID: "k1", SignKey: []byte("sign"), EncryptKey: []byte("short")},
+			}},
+		},
+		{
+			name: "encrypt without encrypt key",
+			conf: types.EnvelopeConfig{KeyID: "k1", Encrypt: true, Keys: []types.EnvelopeKey{
+				{ID: "k1", SignKey: []byte("sign")},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.conf); !errors.Is(err, types.ErrInvalidConfig()) {
+				t.Fatalf("create envelope returns %v, want %v", err, types.ErrInvalidConfig())
+			}
+		})
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	tests := []struct {
+		name    string
+		sealKey string
+		encrypt bool
+		openKey string
+		content []byte
+		visible bool
+	}{
+		{name: "signed", sealKey: "k2", openKey: "k2", content: []byte("hello"), visible: true},
+		{name: "encrypted", sealKey: "k2", encrypt: true, openKey: "k2", content: []byte("hello")},
+		{name: "opened by rotated key", sealKey: "k1", encrypt: true, openKey: "k2", content: []byte("hello")},
+		{name: "empty content", sealKey: "k2", encrypt: true, openKey: "k2", content: []byte{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := newEnvelope(t, tt.sealKey, tt.encrypt).Seal("msg", tt.content)
+			if err != nil {
+				t.Fatalf("seal failed: %v", err)
+			}
+
+			encoded := base64.StdEncoding.EncodeToString(tt.content)
+			if len(tt.content) > 0 && bytes.Contains(sealed, []byte(encoded)) != tt.visible {
+				t.Fatalf("content visible in sealed data is %v, want %v", !tt.visible, tt.visible)
+			}
+
+			content, err := newEnvelope(t, tt.openKey, false).Open("msg", sealed)
+			if err != nil {
+				t.Fatalf("open failed: %v", err)
+			}
+
+			if !bytes.Equal(content, tt.content) {
+				t.Fatalf("opened content %q, want %q", content, tt.content)
+			}
+		})
+	}
+}
+
+// reseal modifies the envelope of the sealed data, and signs it again with key if not nil.
+func reseal(t *testing.T, data []byte, key *types.EnvelopeKey, modify func(*sealed)) []byte {
+	t.Helper()
+
+	env := new(sealed)
+	if err := json.Unmarshal(data[len(magic):], env); err != nil {
+		t.Fatalf("unmarshal envelope failed: %v", err)
+	}
+
+	modify(env)
+
+	if key != nil {
+		env.Signature = base64.StdEncoding.EncodeToString(sign(key.SignKey, "msg", env))
+	}
+
+	resealed, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("marshal envelope failed: %v", err)
+	}
+
+	return append([]byte(magic), resealed...)
+}
+
+func TestOpenRejected(t *testing.T) {
+	seal := func() []byte {
+		data, err := newEnvelope(t, "k2", true).Seal("msg", []byte("hello"))
+		if err != nil {
+			t.Fatalf("seal failed: %v", err)
+		}
+
+		return data
+	}
+
+	tests := []struct {
+		name      string
+		messageID string
+		data      []byte
+	}{
+		{name: "not sealed", messageID: "msg", data: []byte("hello")},
+		{name: "invalid json", messageID: "msg", data: []byte(magic + "{")},
+		{name: "other message id", messageID: "other", data: seal()},
+		{
+			name:      "unknown key",
+			messageID: "msg",
+			data:      reseal(t, seal(), nil, func(env *sealed) { env.KeyID = "k3" }),
+		},
+		{
+			name:      "tampered payload",
+			messageID: "msg",
+			data: reseal(t, seal(), nil, func(env *sealed) {
+				env.Payload = base64.StdEncoding.EncodeToString([]byte("forged"))
+			}),
+		},
+		{
+			name:      "expired",
+			messageID: "msg",
+			data: reseal(t, seal(), &newKey, func(env *sealed) {
+				env.Timestamp = time.Now().Add(-2 * defaultReplayWindow).UnixMilli()
+			}),
+		},
+		{
+			name:      "too far ahead",
+			messageID: "msg",
+			data: reseal(t, seal(), &newKey, func(env *sealed) {
+				env.Timestamp = time.Now().Add(2 * defaultReplayWindow).UnixMilli()
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newEnvelope(t, "k2", false).Open(tt.messageID, tt.data)
+			if !errors.Is(err, types.ErrEnvelopeRejected()) {
+				t.Fatalf("open returns %v, want %v", err, types.ErrEnvelopeRejected())
+			}
+		})
+	}
+}
+
+func TestOpenReplayed(t *testing.T) {
+	e := newEnvelope(t, "k2", true)
+
+	sealed, err := e.Seal("msg", []byte("hello"))
+	if err != nil {
+		t.Fatalf("seal failed: %v", err)
+	}
+
+	if _, err := e.Open("msg", sealed); err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+
+	if _, err := e.Open("msg", sealed); !errors.Is(err, types.ErrEnvelopeRejected()) {
+		t.Fatalf("open replayed message returns %v, want %v", err, types.ErrEnvelopeRejected())
+	}
+}
+
+func TestRememberExpire(t *testing.T) {
+	e := newEnvelope(t, "k2", false)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if !e.remember(fmt.Sprintf("nonce-%d", i), now.Add(time.Duration(i)*time.Second)) {
+			t.Fatalf("nonce-%d is seen before remembered", i)
+		}
+	}
+
+	if e.remember("nonce-1", now.Add(time.Minute)) {
+		t.Fatal("nonce-1 is not seen in replay window")
+	}
+
+	// the nonces are kept for twice of the window, only the first one is expired.
+	later := now.Add(2*defaultReplayWindow + time.Second/2)
+	if !e.remember("nonce-0", later) {
+		t.Fatal("nonce-0 is still seen after expired")
+	}
+
+	if e.remember("nonce-2", later) {
+		t.Fatal("nonce-2 is expired before its time")
+	}
+
+	if len(e.seen) != len(e.expiries) {
+		t.Fatalf("%d nonces seen but %d queued for expiring", len(e.seen), len(e.expiries))
+	}
+}
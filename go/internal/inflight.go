@@ -0,0 +1,82 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package internal
+
+import (
+	"context"
+	"sync"
+)
+
+// InFlight counts the in-flight works and waits for them to finish.
+type InFlight struct {
+	count int
+
+	// idle is closed when count drops to 0, it's renewed when count rises from 0.
+	idle  chan struct{}
+	mutex sync.Mutex
+}
+
+// Add marks a work started.
+func (f *InFlight) Add() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.count == 0 {
+		f.idle = make(chan struct{})
+	}
+
+	f.count++
+}
+
+// Done marks a work finished.
+func (f *InFlight) Done() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.count--
+	if f.count == 0 {
+		close(f.idle)
+	}
+}
+
+// Count returns the number of in-flight works.
+func (f *InFlight) Count() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.count
+}
+
+// Wait waits until all in-flight works finished or the context is done,
+// returns the number of works still in flight.
+func (f *InFlight) Wait(ctx context.Context) int {
+	f.mutex.Lock()
+	if f.count == 0 {
+		f.mutex.Unlock()
+		return 0
+	}
+
+	idle := f.idle
+	f.mutex.Unlock()
+
+	select {
+	case <-idle:
+		return 0
+
+	case <-ctx.Done():
+		return f.Count()
+	}
+}
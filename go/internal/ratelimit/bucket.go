@@ -0,0 +1,85 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+// Package ratelimit provides the token bucket for rate limiting.
+package ratelimit
+
+import (
+	"time"
+)
+
+// Bucket is a token bucket, it's not goroutine safe and should be protected by the caller.
+type Bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewBucket creates a full bucket which refills rate tokens per second up to burst.
+func NewBucket(rate, burst float64, now time.Time) *Bucket {
+	return &Bucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// Take takes n tokens if they are available, returns false if not.
+// a full bucket always allows the taking even if n is larger than burst, and goes into debt.
+func (b *Bucket) Take(n float64, now time.Time) bool {
+	b.refill(now)
+
+	if b.tokens < n && b.tokens < b.burst {
+		return false
+	}
+
+	b.tokens -= n
+
+	return true
+}
+
+// Available returns whether n tokens could be taken now.
+func (b *Bucket) Available(n float64, now time.Time) bool {
+	b.refill(now)
+
+	return b.tokens >= n || b.tokens >= b.burst
+}
+
+// Reserve takes n tokens into debt, returns how long to wait until the debt is paid.
+func (b *Bucket) Reserve(n float64, now time.Time) time.Duration {
+	if b.Take(n, now) {
+		return 0
+	}
+
+	wait := time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+	b.tokens -= n
+
+	return wait
+}
+
+// Refund returns n tokens taken or reserved but not used, up to burst.
+func (b *Bucket) Refund(n float64, now time.Time) {
+	b.refill(now)
+	b.tokens = min(b.burst, b.tokens+n)
+}
+
+func (b *Bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketTake(t *testing.T) {
+	start := time.Now()
+
+	tests := []struct {
+		name  string
+		takes []float64
+		at    []time.Duration
+		want  []bool
+	}{
+		{
+			name:  "burst then empty",
+			takes: []float64{1, 1, 1, 1},
+			at:    []time.Duration{0, 0, 0, 0},
+			want:  []bool{true, true, true, false},
+		},
+		{
+			name:  "refilled by rate",
+			takes: []float64{3, 1, 1},
+			at:    []time.Duration{0, 500 * time.Millisecond, time.Second},
+			want:  []bool{true, false, true},
+		},
+		{
+			name:  "refilled up to burst",
+			takes: []float64{3, 3, 1},
+			at:    []time.Duration{0, time.Hour, time.Hour},
+			want:  []bool{true, true, false},
+		},
+		{
+			name:  "full bucket allows larger taking",
+			takes: []float64{10, 1},
+			at:    []time.Duration{0, 3 * time.Second},
+			want:  []bool{true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 1 token per second, up to 3.
+			b := NewBucket(1, 3, start)
+
+			for i, n := range tt.takes {
+				if got := b.Take(n, start.Add(tt.at[i])); got != tt.want[i] {
+					t.Fatalf("take #%d of %v tokens returns %v, want %v", i, n, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBucketAvailable(t *testing.T) {
+	start := time.Now()
+	b := NewBucket(1, 3, start)
+
+	if !b.Available(3, start) {
+		t.Fatal("full bucket has no 3 tokens")
+	}
+
+	// Available does not take.
+	if !b.Take(3, start) {
+		t.Fatal("take 3 tokens from full bucket failed")
+	}
+
+	if b.Available(1, start) {
+		t.Fatal("empty bucket has tokens")
+	}
+
+	if !b.Available(1, start.Add(time.Second)) {
+		t.Fatal("bucket is not refilled after 1 second")
+	}
+}
+
+func TestBucketReserve(t *testing.T) {
+	start := time.Now()
+	b := NewBucket(10, 1, start)
+
+	tests := []struct {
+		n    float64
+		wait time.Duration
+	}{
+		{n: 1, wait: 0},
+		{n: 1, wait: 100 * time.Millisecond},
+		{n: 2, wait: 300 * time.Millisecond},
+	}
+
+	for i, tt := range tests {
+		if wait := b.Reserve(tt.n, start); wait != tt.wait {
+			t.Fatalf("reserve #%d of %v tokens waits %s, want %s", i, tt.n, wait, tt.wait)
+		}
+	}
+
+	// the debt is paid after waiting.
+	if !b.Take(1, start.Add(400*time.Millisecond)) {
+		t.Fatal("bucket is not refilled after the debt is paid")
+	}
+}
+
+func TestBucketRefund(t *testing.T) {
+	start := time.Now()
+	b := NewBucket(10, 1, start)
+
+	b.Reserve(1, start)
+	b.Reserve(1, start)
+
+	b.Refund(1, start)
+
+	if !b.Available(1, start.Add(100*time.Millisecond)) {
+		t.Fatal("refunded reservation still waits")
+	}
+
+	// refunding never fills over burst.
+	b.Refund(10, start)
+
+	if !b.Take(1, start) || b.Available(1, start) {
+		t.Fatal("bucket is filled over burst by refunding")
+	}
+}
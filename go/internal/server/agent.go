@@ -0,0 +1,71 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package server
+
+import (
+	"context"
+	"net/http"
+)
+
+// Agent provides all agent methods in gse server.
+type Agent interface {
+	// ListAgentState lists the state of agents.
+	ListAgentState(ctx context.Context, request *ListAgentReq, header http.Header) (*ListAgentStateResp, error)
+
+	// ListAgentInfo lists the info of agents.
+	ListAgentInfo(ctx context.Context, request *ListAgentReq, header http.Header) (*ListAgentInfoResp, error)
+}
+
+const (
+	agentPathListAgentState = clusterPathPrefix + "/list_agent_state"
+	agentPathListAgentInfo  = clusterPathPrefix + "/list_agent_info"
+)
+
+// ListAgentState lists the state of agents.
+func (c *client) ListAgentState(ctx context.Context, request *ListAgentReq, header http.Header) (
+	*ListAgentStateResp, error) {
+
+	resp := new(ListAgentStateResp)
+	if err := c.listAgent(ctx, agentPathListAgentState, request, header, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ListAgentInfo lists the info of agents.
+func (c *client) ListAgentInfo(ctx context.Context, request *ListAgentReq, header http.Header) (
+	*ListAgentInfoResp, error) {
+
+	resp := new(ListAgentInfoResp)
+	if err := c.listAgent(ctx, agentPathListAgentInfo, request, header, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *client) listAgent(ctx context.Context, path string, request *ListAgentReq, header http.Header,
+	resp any) error {
+
+	return c.post().
+		Context(ctx).
+		SubResourcef(path).
+		Headers(header).
+		Body(request).
+		Do().
+		Into(resp)
+}
@@ -22,6 +22,9 @@ import "github.com/TencentBlueKing/bk-gse-sdk/go/internal"
 type Client interface {
 	// Cluster provides cluster handling methods.
 	Cluster() Cluster
+
+	// Agent provides agent handling methods.
+	Agent() Agent
 }
 
 // New creates a new client.
@@ -40,12 +43,19 @@ func (c *client) Cluster() Cluster {
 	return c
 }
 
+// Agent provides agent handling methods.
+func (c *client) Agent() Agent {
+	return c
+}
+
 // Post returns a new request with method POST.
 func (c *client) post() *Request {
 	return &Request{
-		client:   c.conf.Client,
-		method:   "POST",
-		headers:  internal.CopyHeaders(c.conf.BaseHeader),
-		basePath: c.conf.BaseURL,
+		client:       c.conf.Client,
+		timeout:      c.conf.Timeout,
+		interceptors: c.conf.Interceptors,
+		method:       "POST",
+		headers:      internal.CopyHeaders(c.conf.BaseHeader),
+		basePath:     c.conf.BaseURL,
 	}
 }
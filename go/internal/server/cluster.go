@@ -37,12 +37,13 @@ const (
 )
 
 // DispatchMessage dispatch message to agents through cluster.
-func (c *client) DispatchMessage(_ context.Context, request *ClusterDispatchMessageReq, header http.Header) (
+func (c *client) DispatchMessage(ctx context.Context, request *ClusterDispatchMessageReq, header http.Header) (
 	*ClusterDispatchMessageResp, error) {
 
 	resp := new(ClusterDispatchMessageResp)
 
 	err := c.post().
+		Context(ctx).
 		SubResourcef(clusterPathDispatchMessage).
 		Headers(header).
 		Body(request).
@@ -57,12 +58,13 @@ func (c *client) DispatchMessage(_ context.Context, request *ClusterDispatchMess
 }
 
 // DispatchMultiMessage dispatch multi message to agents through cluster.
-func (c *client) DispatchMultiMessage(_ context.Context, request *ClusterDispatchMultiMessageReq, header http.Header) (
+func (c *client) DispatchMultiMessage(ctx context.Context, request *ClusterDispatchMultiMessageReq, header http.Header) ( // nolint:lll
 	*ClusterDispatchMultiMessageResp, error) {
 
 	resp := new(ClusterDispatchMultiMessageResp)
 
 	err := c.post().
+		Context(ctx).
 		SubResourcef(clusterPathDispatchMultiMessage).
 		Headers(header).
 		Body(request).
@@ -17,6 +17,7 @@ package server
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
 )
@@ -32,6 +33,12 @@ type Config struct {
 	// Client is the HTTP client to use for requests.
 	Client *http.Client
 
+	// Timeout is the timeout of every request, 0 means no timeout.
+	Timeout time.Duration
+
+	// Interceptors is the interceptor chain of every request, the first one is the outermost.
+	Interceptors []Interceptor
+
 	// Logger is the logger to use for requests.
 	Logger types.Logger
 }
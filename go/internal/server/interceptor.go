@@ -0,0 +1,65 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package server
+
+import (
+	"context"
+	"net/http"
+)
+
+// Call describes a request to server seen by interceptors.
+// the interceptors could change the header and body before calling the next one, e.g. inject tracing headers.
+type Call struct {
+	Method string
+
+	// Path is the api path, e.g. /api/v2/cluster/dispatch_message.
+	Path string
+
+	// URL is the full url of request.
+	URL string
+
+	Header http.Header
+	Body   []byte
+}
+
+// Response describes a response from server seen by interceptors.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+
+	// Body is the whole body if the status code is 200, otherwise it's truncated.
+	Body []byte
+}
+
+// Invoker sends the call, the error is the failure without response, e.g. network error.
+// the response with non-200 status code is not an error in invoker.
+type Invoker func(ctx context.Context, call *Call) (*Response, error)
+
+// Interceptor intercepts the call, it should call next to continue, or return without calling to short-circuit.
+type Interceptor func(ctx context.Context, call *Call, next Invoker) (*Response, error)
+
+// chain chains the interceptors in order around invoker, the first one is the outermost.
+func chain(interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+
+		invoker = func(ctx context.Context, call *Call) (*Response, error) {
+			return interceptor(ctx, call, next)
+		}
+	}
+
+	return invoker
+}
@@ -71,3 +71,38 @@ type ClusterRespondMessage struct {
 	AgentID   string `json:"bk_agent_id"`
 	Content   string `json:"content"`
 }
+
+// ListAgentReq defines the http request body of listing agent state or info.
+type ListAgentReq struct {
+	AgentIDList []string `json:"agent_id_list"`
+}
+
+// ListAgentStateResp defines the http response body of listing agent state.
+type ListAgentStateResp struct {
+	Code    int               `json:"code"`
+	Message string            `json:"message"`
+	Data    []*AgentStateItem `json:"data"`
+}
+
+// AgentStateItem defines the state of an agent.
+type AgentStateItem struct {
+	AgentID    string `json:"bk_agent_id"`
+	CloudID    int    `json:"bk_cloud_id"`
+	StatusCode int    `json:"status_code"`
+}
+
+// ListAgentInfoResp defines the http response body of listing agent info.
+type ListAgentInfoResp struct {
+	Code    int              `json:"code"`
+	Message string           `json:"message"`
+	Data    []*AgentInfoItem `json:"data"`
+}
+
+// AgentInfoItem defines the info of an agent.
+type AgentInfoItem struct {
+	AgentID    string `json:"bk_agent_id"`
+	CloudID    int    `json:"bk_cloud_id"`
+	Version    string `json:"version"`
+	RunMode    int    `json:"run_mode"`
+	StatusCode int    `json:"status_code"`
+}
@@ -17,18 +17,30 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
 )
 
+// maxDrainBytes is the max bytes to drain from the unused response body, so that the connection could be reused.
+const maxDrainBytes = 64 * 1024
+
 // Request handle the http request things.
 type Request struct {
 	client *http.Client
 
+	// timeout is Config.Timeout applied on every request, a per call deadline is given by ctx.
+	ctx          context.Context
+	timeout      time.Duration
+	interceptors []Interceptor
+
 	method  string
 	headers http.Header
 	body    []byte
@@ -87,33 +99,97 @@ func (r *Request) Headers(header http.Header) *Request {
 	return r
 }
 
-// Do send request.
+// Context set request context, the request is canceled when the context is done.
+func (r *Request) Context(ctx context.Context) *Request {
+	r.ctx = ctx
+
+	return r
+}
+
+// Do send request, and read the whole response body.
+// the error caused by canceled context or timeout is joined with types.ErrContextDone.
 func (r *Request) Do() *Result {
 	if r.processErr != nil {
 		return &Result{processErr: r.processErr}
 	}
 
-	req, err := http.NewRequest(r.method, r.getURL(), bytes.NewReader(r.body))
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	call := &Call{
+		Method: r.method,
+		Path:   "/" + strings.TrimLeft(r.subPath, "/"),
+		URL:    r.getURL(),
+		Header: r.headers,
+		Body:   r.body,
+	}
+
+	resp, err := chain(r.interceptors, r.invoke)(ctx, call)
 	if err != nil {
-		r.processFail(err)
+		r.processFail(contextError(ctx, err))
 
 		return &Result{processErr: r.processErr}
 	}
 
-	req.Header = r.headers
+	if resp.StatusCode != http.StatusOK {
+		return &Result{processErr: &types.HTTPStatusError{URL: call.URL, StatusCode: resp.StatusCode}}
+	}
+
+	return &Result{body: resp.Body}
+}
 
-	resp, err := r.client.Do(req)
+// invoke sends the call through http client, and reads the response body.
+func (r *Request) invoke(ctx context.Context, call *Call) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, call.Method, call.URL, bytes.NewReader(call.Body))
 	if err != nil {
-		r.processFail(err)
+		return nil, err
+	}
 
-		return &Result{processErr: r.processErr}
+	if call.Header != nil {
+		req.Header = call.Header
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
 	}
 
+	defer drainAndClose(resp.Body)
+
+	reader := io.Reader(resp.Body)
 	if resp.StatusCode != http.StatusOK {
-		return &Result{processErr: fmt.Errorf("request to %s failed, status code: %d", r.getURL(), resp.StatusCode)}
+		reader = io.LimitReader(resp.Body, maxDrainBytes)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, nil
+}
+
+// contextError joins err with types.ErrContextDone if it's caused by the done context.
+func contextError(ctx context.Context, err error) error {
+	if ctx.Err() == nil {
+		return err
 	}
 
-	return &Result{resp: resp}
+	return errors.Join(types.ErrContextDone(), ctx.Err(), err)
+}
+
+// drainAndClose drains the rest of body and closes it, so that the connection could be reused.
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, maxDrainBytes))
+	_ = body.Close()
 }
 
 func (r *Request) getURL() string {
@@ -138,7 +214,7 @@ func (r *Request) processFail(err error) {
 type Result struct {
 	processErr error
 
-	resp *http.Response
+	body []byte
 }
 
 // Into parses the response body into v.
@@ -147,14 +223,9 @@ func (r *Result) Into(responseBody any) error {
 		return r.processErr
 	}
 
-	if r.resp == nil {
+	if r.body == nil {
 		return errors.New("no response")
 	}
 
-	data, err := io.ReadAll(r.resp.Body)
-	if err != nil {
-		return err
-	}
-
-	return json.Unmarshal(data, responseBody)
+	return json.Unmarshal(r.body, responseBody)
 }
@@ -0,0 +1,138 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package internal
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// Stats collects the delivery statistics of a client, it's safe for concurrent use.
+type Stats struct {
+	send    types.DirectionStats
+	recv    types.DirectionStats
+	dataIDs map[uint32]*types.DirectionStats
+
+	keepaliveSentAt time.Time
+	keepaliveRTT    time.Duration
+
+	mutex sync.Mutex
+}
+
+// NewStats creates a new Stats.
+func NewStats() *Stats {
+	return &Stats{
+		dataIDs: make(map[uint32]*types.DirectionStats),
+	}
+}
+
+// Sent records a frame of size bytes sent to agent, or the failure.
+func (s *Stats) Sent(size int, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	record(&s.send, size, err, time.Now())
+}
+
+// SentDataID records a report of size bytes sent to the data-id, or the failure.
+func (s *Stats) SentDataID(dataID uint32, size int, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	record(&s.send, size, err, now)
+
+	stats, ok := s.dataIDs[dataID]
+	if !ok {
+		stats = new(types.DirectionStats)
+		s.dataIDs[dataID] = stats
+	}
+
+	record(stats, size, err, now)
+}
+
+// Received records a frame of size bytes received from agent, or the failure of handling it.
+func (s *Stats) Received(size int, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	record(&s.recv, size, err, time.Now())
+}
+
+// KeepaliveSent records the time of keepalive request sent.
+func (s *Stats) KeepaliveSent(now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.keepaliveSentAt = now
+}
+
+// KeepaliveReceived records the round-trip time of keepalive by the response time.
+func (s *Stats) KeepaliveReceived(now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.keepaliveSentAt.IsZero() {
+		return
+	}
+
+	s.keepaliveRTT = now.Sub(s.keepaliveSentAt)
+	s.keepaliveSentAt = time.Time{}
+}
+
+// Snapshot returns a copy of the statistics, reconnects is from the agent client.
+func (s *Stats) Snapshot(reconnects uint64) types.Stats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := types.Stats{
+		Send:         clone(s.send),
+		Recv:         clone(s.recv),
+		DataIDs:      make(map[uint32]types.DirectionStats, len(s.dataIDs)),
+		Reconnects:   reconnects,
+		KeepaliveRTT: s.keepaliveRTT,
+	}
+
+	for dataID, d := range s.dataIDs {
+		stats.DataIDs[dataID] = clone(*d)
+	}
+
+	return stats
+}
+
+func record(stats *types.DirectionStats, size int, err error, now time.Time) {
+	if err != nil {
+		if stats.Errors == nil {
+			stats.Errors = make(map[types.ErrorKind]uint64)
+		}
+
+		stats.Errors[types.ErrorKindOf(err)]++
+
+		return
+	}
+
+	stats.Frames++
+	stats.Bytes += uint64(size)
+	stats.LastSuccessAt = now
+}
+
+func clone(stats types.DirectionStats) types.DirectionStats {
+	stats.Errors = maps.Clone(stats.Errors)
+	return stats
+}
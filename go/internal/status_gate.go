@@ -0,0 +1,192 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package internal
+
+import (
+	"context"
+	"sync"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// StatusGate admits the sending according to the agent status and policy.
+type StatusGate struct {
+	policy    types.AgentStatusPolicy
+	queueSize int
+
+	// changed is closed and renewed when status changed, to wake up the waiting senders.
+	status  types.AgentStatus
+	changed chan struct{}
+
+	// queue is the sendings deferred with queue policy, they are sent in order by drain when agent is running.
+	queue    []func()
+	draining bool
+
+	mutex sync.Mutex
+}
+
+// NewStatusGate creates a new StatusGate, the status is unknown until updated.
+// queueSize limits the number of queued sendings with queue policy.
+func NewStatusGate(policy types.AgentStatusPolicy, queueSize int) *StatusGate {
+	return &StatusGate{
+		policy:    policy,
+		queueSize: queueSize,
+		status:    types.AgentStatusUnknown,
+		changed:   make(chan struct{}),
+	}
+}
+
+// Update updates the agent status, the queued sendings are sent when the agent is running.
+func (g *StatusGate) Update(status types.AgentStatus) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.status == status {
+		return
+	}
+
+	g.status = status
+	close(g.changed)
+	g.changed = make(chan struct{})
+
+	g.startDrain()
+}
+
+// Reset forgets the agent status when the connection to agent is lost or terminated,
+// the status is unknown until updated by the next keepalive response. the queued sendings are kept.
+func (g *StatusGate) Reset() {
+	g.Update(types.AgentStatusUnknown)
+}
+
+// Status returns the agent status.
+func (g *StatusGate) Status() types.AgentStatus {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.status
+}
+
+// Clear drops the queued sendings, returns the number of dropped ones. it's called when terminated.
+func (g *StatusGate) Clear() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	dropped := len(g.queue)
+	g.queue = nil
+
+	return dropped
+}
+
+// Admit returns nil if the sending could go on, it waits while the agent is unavailable with wait policy.
+// with queue policy, the deferred function is queued while the agent is unavailable or there are queued ones
+// before it, and it's called in order when the agent is running again.
+// the failure is *types.AgentStatusError, whose Queued is true if the deferred function is queued.
+func (g *StatusGate) Admit(ctx context.Context, deferred func()) error {
+	if g.policy == types.AgentStatusPolicyIgnore {
+		return nil
+	}
+
+	for {
+		g.mutex.Lock()
+		status, changed := g.status, g.changed
+
+		if g.policy == types.AgentStatusPolicyQueue {
+			err := g.enqueue(deferred)
+			g.mutex.Unlock()
+
+			return err
+		}
+
+		g.mutex.Unlock()
+
+		switch {
+		case status == types.AgentStatusDamage:
+			return &types.AgentStatusError{Status: status}
+
+		case unavailable(status):
+			if g.policy == types.AgentStatusPolicyReject {
+				return &types.AgentStatusError{Status: status}
+			}
+
+		default:
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return &types.AgentStatusError{Status: status, Err: types.ErrContextDone()}
+		}
+	}
+}
+
+// enqueue queues the deferred function with queue policy, it's called with lock.
+func (g *StatusGate) enqueue(deferred func()) error {
+	if g.status == types.AgentStatusDamage {
+		return &types.AgentStatusError{Status: g.status}
+	}
+
+	// the sending goes on if nothing is queued before it, otherwise it's queued to keep the order.
+	if !unavailable(g.status) && len(g.queue) == 0 && !g.draining {
+		return nil
+	}
+
+	if len(g.queue) >= g.queueSize {
+		return &types.AgentStatusError{Status: g.status, Err: types.ErrQueueFull()}
+	}
+
+	g.queue = append(g.queue, deferred)
+	g.startDrain()
+
+	return &types.AgentStatusError{Status: g.status, Queued: true}
+}
+
+// startDrain starts sending the queued ones if the agent is running, it's called with lock.
+func (g *StatusGate) startDrain() {
+	if g.status != types.AgentStatusRunning || len(g.queue) == 0 || g.draining {
+		return
+	}
+
+	g.draining = true
+
+	go g.drain()
+}
+
+// drain sends the queued ones in order until the queue is empty or the agent is not running.
+func (g *StatusGate) drain() {
+	for {
+		g.mutex.Lock()
+		if g.status != types.AgentStatusRunning || len(g.queue) == 0 {
+			g.draining = false
+			g.mutex.Unlock()
+
+			return
+		}
+
+		deferred := g.queue[0]
+		g.queue[0] = nil
+		g.queue = g.queue[1:]
+		g.mutex.Unlock()
+
+		deferred()
+	}
+}
+
+// unavailable returns true if the sending should be deferred in the status.
+func unavailable(status types.AgentStatus) bool {
+	return status == types.AgentStatusBusy || status == types.AgentStatusUpgrading ||
+		status == types.AgentStatusStopping
+}
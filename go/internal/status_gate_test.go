@@ -0,0 +1,142 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+func TestStatusGateAdmit(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy types.AgentStatusPolicy
+		status types.AgentStatus
+		admit  bool
+	}{
+		{name: "ignore damage", policy: types.AgentStatusPolicyIgnore, status: types.AgentStatusDamage, admit: true},
+		{name: "wait unknown", policy: types.AgentStatusPolicyWait, status: types.AgentStatusUnknown, admit: true},
+		{name: "wait running", policy: types.AgentStatusPolicyWait, status: types.AgentStatusRunning, admit: true},
+		{name: "wait damage", policy: types.AgentStatusPolicyWait, status: types.AgentStatusDamage, admit: false},
+		{name: "reject busy", policy: types.AgentStatusPolicyReject, status: types.AgentStatusBusy, admit: false},
+		{name: "reject running", policy: types.AgentStatusPolicyReject, status: types.AgentStatusRunning, admit: true},
+		{name: "queue running", policy: types.AgentStatusPolicyQueue, status: types.AgentStatusRunning, admit: true},
+		{name: "queue damage", policy: types.AgentStatusPolicyQueue, status: types.AgentStatusDamage, admit: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewStatusGate(tt.policy, 1)
+			g.Update(tt.status)
+
+			err := g.Admit(context.Background(), nil)
+			if (err == nil) != tt.admit {
+				t.Fatalf("admit returns %v, want admitted %v", err, tt.admit)
+			}
+
+			if err != nil && !errors.Is(err, types.ErrAgentUnavailable()) {
+				t.Fatalf("admit returns %v, want %v", err, types.ErrAgentUnavailable())
+			}
+		})
+	}
+}
+
+func TestStatusGateWaitUntilReset(t *testing.T) {
+	g := NewStatusGate(types.AgentStatusPolicyWait, 1)
+	g.Update(types.AgentStatusBusy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := g.Admit(ctx, nil); !errors.Is(err, types.ErrContextDone()) {
+		t.Fatalf("admit while busy returns %v, want %v", err, types.ErrContextDone())
+	}
+
+	admitted := make(chan error, 1)
+	go func() { admitted <- g.Admit(context.Background(), nil) }()
+
+	g.Reset()
+
+	if err := <-admitted; err != nil {
+		t.Fatalf("admit after reset returns %v", err)
+	}
+
+	if status := g.Status(); status != types.AgentStatusUnknown {
+		t.Fatalf("status is %s after reset, want %s", status, types.AgentStatusUnknown)
+	}
+}
+
+func TestStatusGateQueue(t *testing.T) {
+	g := NewStatusGate(types.AgentStatusPolicyQueue, 2)
+	g.Update(types.AgentStatusBusy)
+
+	sent := make(chan int, 3)
+
+	for i := 0; i < 2; i++ {
+		i := i
+		err := g.Admit(context.Background(), func() { sent <- i })
+		if !types.IsQueued(err) {
+			t.Fatalf("admit %d while busy returns %v, want queued", i, err)
+		}
+	}
+
+	if err := g.Admit(context.Background(), func() { sent <- 2 }); !errors.Is(err, types.ErrQueueFull()) {
+		t.Fatalf("admit over queue size returns %v, want %v", err, types.ErrQueueFull())
+	}
+
+	// the queued ones are kept while the agent is unknown, and sent in order once it's running.
+	g.Reset()
+	g.Update(types.AgentStatusRunning)
+
+	for want := 0; want < 2; want++ {
+		select {
+		case got := <-sent:
+			if got != want {
+				t.Fatalf("queued sending %d is sent, want %d", got, want)
+			}
+
+		case <-time.After(time.Second):
+			t.Fatalf("queued sending %d is not sent after running", want)
+		}
+	}
+
+	if err := g.Admit(context.Background(), nil); err != nil {
+		t.Fatalf("admit after drained returns %v", err)
+	}
+}
+
+func TestStatusGateClear(t *testing.T) {
+	g := NewStatusGate(types.AgentStatusPolicyQueue, 2)
+	g.Update(types.AgentStatusUpgrading)
+
+	if err := g.Admit(context.Background(), func() { t.Error("cleared sending is sent") }); !types.IsQueued(err) {
+		t.Fatalf("admit while upgrading returns %v, want queued", err)
+	}
+
+	if dropped := g.Clear(); dropped != 1 {
+		t.Fatalf("clear drops %d sendings, want 1", dropped)
+	}
+
+	g.Update(types.AgentStatusRunning)
+
+	if err := g.Admit(context.Background(), nil); err != nil {
+		t.Fatalf("admit after cleared returns %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentlog
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// NewDefaultConfig creates a default configuration for log shipper.
+func NewDefaultConfig() *Config {
+	return &Config{
+		DataID:            0,
+		Level:             slog.LevelInfo,
+		AddSource:         false,
+		LineLevel:         nil,
+		MaxRecords:        defaultMaxRecords,
+		MaxBytes:          defaultMaxBytes,
+		MaxPendingRecords: defaultMaxPendingRecords,
+		Linger:            defaultLinger,
+		ReportTimeout:     defaultReportTimeout,
+		Fallback:          os.Stderr,
+	}
+}
+
+const (
+	defaultMaxRecords        = 100
+	defaultMaxBytes          = 1024 * 1024
+	defaultMaxPendingRecords = 10000
+	defaultLinger            = 1 * time.Second
+	defaultReportTimeout     = 5 * time.Second
+)
+
+// Config defines the configuration for log shipper.
+// there is no types.Logger in it by design, the shipper never logs through the SDK logger,
+// which may be backed by the shipper itself, all failures go to the Fallback.
+type Config struct {
+	// DataID describes the data-id which the logs are reported to.
+	DataID uint32
+
+	// Level describes the minimum level of the records to report.
+	Level slog.Leveler
+
+	// AddSource describes whether to add the source code position of the slog records.
+	AddSource bool
+
+	// LineLevel describes how to get the level of a line written to Writer, nil means all lines are reported.
+	LineLevel func(line []byte) slog.Level
+
+	// MaxRecords describes the max number of records in a single report.
+	MaxRecords int
+
+	// MaxBytes describes the max size in bytes of a single report, larger record is written to Fallback.
+	MaxBytes int
+
+	// MaxPendingRecords describes the max number of records waiting for reporting,
+	// the records over it are written to Fallback instead of blocking the caller.
+	MaxPendingRecords int
+
+	// Linger describes how long a record could wait before reported.
+	Linger time.Duration
+
+	// ReportTimeout describes the timeout of a single report.
+	ReportTimeout time.Duration
+
+	// Fallback describes the local sink of the records which could not be reported, nil means discarding them.
+	Fallback io.Writer
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	if c.DataID == 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("data id is 0"))
+	}
+
+	if c.Level == nil {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("level is empty"))
+	}
+
+	if c.MaxRecords <= 0 || c.MaxBytes <= 0 || c.MaxPendingRecords <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("max records, max bytes or max pending records is 0"))
+	}
+
+	if c.Linger <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("linger is 0"))
+	}
+
+	if c.ReportTimeout <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("report timeout is 0"))
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentlog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// handler filters the records by level and encodes them by the inner json handler into the shipper.
+type handler struct {
+	shipper *shipper
+	inner   slog.Handler
+}
+
+// Enabled reports whether the level is over the minimum level.
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.shipper.conf.Level.Level()
+}
+
+// Handle ships the record.
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	return h.inner.Handle(ctx, record)
+}
+
+// WithAttrs returns a new handler with the attrs.
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{shipper: h.shipper, inner: h.inner.WithAttrs(attrs)}
+}
+
+// WithGroup returns a new handler with the group.
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{shipper: h.shipper, inner: h.inner.WithGroup(name)}
+}
+
+// recordWriter receives the encoded records from the inner json handler, which writes a whole record once.
+type recordWriter struct {
+	shipper *shipper
+}
+
+func (w recordWriter) Write(p []byte) (int, error) {
+	w.shipper.enqueue(bytes.TrimSuffix(p, []byte{'\n'}))
+	return len(p), nil
+}
+
+// lineWriter ships every line as a record.
+type lineWriter struct {
+	shipper *shipper
+
+	// buf holds the last line without '\n'.
+	buf   []byte
+	mutex sync.Mutex
+}
+
+// Write ships the complete lines in p, the last line without '\n' waits for the following writes.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.buf = append(w.buf, p...)
+
+	for {
+		pos := bytes.IndexByte(w.buf, '\n')
+		if pos < 0 {
+			break
+		}
+
+		w.ship(w.buf[:pos])
+		w.buf = w.buf[pos+1:]
+	}
+
+	// ship the overlong line in place, it could never fit in a report.
+	if len(w.buf) > w.shipper.conf.MaxBytes {
+		w.ship(w.buf)
+		w.buf = nil
+	}
+
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+
+	return len(p), nil
+}
+
+// Close ships the last line without '\n'.
+func (w *lineWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.buf) > 0 {
+		w.ship(w.buf)
+	}
+
+	w.buf = nil
+
+	return nil
+}
+
+func (w *lineWriter) ship(line []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if len(line) == 0 {
+		return
+	}
+
+	if w.shipper.conf.LineLevel != nil && w.shipper.conf.LineLevel(line) < w.shipper.conf.Level.Level() {
+		return
+	}
+
+	w.shipper.enqueue(line)
+}
@@ -0,0 +1,95 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentlog
+
+import (
+	"io"
+	"log/slog"
+	"time"
+)
+
+// OptionFn defines the function type for setting options.
+type OptionFn func(*Config)
+
+// WithDataID sets the data-id which the logs are reported to.
+func WithDataID(dataID uint32) OptionFn {
+	return func(c *Config) {
+		c.DataID = dataID
+	}
+}
+
+// WithLevel sets the minimum level of the records to report.
+func WithLevel(level slog.Leveler) OptionFn {
+	return func(c *Config) {
+		c.Level = level
+	}
+}
+
+// WithAddSource sets whether to add the source code position of the slog records.
+func WithAddSource(addSource bool) OptionFn {
+	return func(c *Config) {
+		c.AddSource = addSource
+	}
+}
+
+// WithLineLevel sets how to get the level of a line written to Writer.
+func WithLineLevel(lineLevel func(line []byte) slog.Level) OptionFn {
+	return func(c *Config) {
+		c.LineLevel = lineLevel
+	}
+}
+
+// WithMaxRecords sets the max number of records in a single report.
+func WithMaxRecords(records int) OptionFn {
+	return func(c *Config) {
+		c.MaxRecords = records
+	}
+}
+
+// WithMaxBytes sets the max size in bytes of a single report.
+func WithMaxBytes(size int) OptionFn {
+	return func(c *Config) {
+		c.MaxBytes = size
+	}
+}
+
+// WithMaxPendingRecords sets the max number of records waiting for reporting.
+func WithMaxPendingRecords(records int) OptionFn {
+	return func(c *Config) {
+		c.MaxPendingRecords = records
+	}
+}
+
+// WithLinger sets how long a record could wait before reported.
+func WithLinger(linger time.Duration) OptionFn {
+	return func(c *Config) {
+		c.Linger = linger
+	}
+}
+
+// WithReportTimeout sets the timeout of a single report.
+func WithReportTimeout(timeout time.Duration) OptionFn {
+	return func(c *Config) {
+		c.ReportTimeout = timeout
+	}
+}
+
+// WithFallback sets the local sink of the records which could not be reported.
+func WithFallback(fallback io.Writer) OptionFn {
+	return func(c *Config) {
+		c.Fallback = fallback
+	}
+}
@@ -0,0 +1,249 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+// Package agentlog provides slog.Handler and io.Writer which ship the plugin's logs through agent data report.
+package agentlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	agentreport "github.com/TencentBlueKing/bk-gse-sdk/go/service/agent-report"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// Reporter reports the data to a data-id, see agentreport.Reporter.
+type Reporter = agentreport.Reporter
+
+// Shipper batches the log records and reports them to the data-id.
+// records are reported in frames joined with '\n', the records failed to report are written to the fallback.
+type Shipper interface {
+	// Handler returns a slog.Handler which ships the records in json.
+	Handler() slog.Handler
+
+	// Writer returns a line-oriented io.WriteCloser which ships every line as a record.
+	// closing it ships the last line without '\n'.
+	Writer() io.WriteCloser
+
+	// Flush reports all the pending records.
+	Flush(ctx context.Context) error
+
+	// Close reports all the pending records and stops the shipper, records after it go to fallback.
+	Close(ctx context.Context) error
+}
+
+// New creates a new log shipper which reports the records by reporter.
+func New(reporter Reporter, opts ...OptionFn) (Shipper, error) {
+	conf := NewDefaultConfig()
+
+	for _, opt := range opts {
+		opt(conf)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &shipper{
+		conf:     conf,
+		reporter: reporter,
+		full:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	go s.holdLinger()
+
+	return s, nil
+}
+
+type shipper struct {
+	conf *Config
+
+	reporter Reporter
+
+	// pending are the records waiting for reporting, full is notified when it reaches MaxRecords.
+	// enqueue never reports in place, so the logs produced during reporting could not recurse.
+	pending [][]byte
+	closed  bool
+	mutex   sync.Mutex
+	full    chan struct{}
+
+	// reportMutex keeps the order of records between the linger reporting and Flush.
+	reportMutex   sync.Mutex
+	fallbackMutex sync.Mutex
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// Handler returns a slog.Handler which ships the records in json.
+func (s *shipper) Handler() slog.Handler {
+	return &handler{
+		shipper: s,
+		inner: slog.NewJSONHandler(recordWriter{shipper: s}, &slog.HandlerOptions{
+			AddSource: s.conf.AddSource,
+			Level:     s.conf.Level,
+		}),
+	}
+}
+
+// Writer returns a line-oriented io.WriteCloser which ships every line as a record.
+func (s *shipper) Writer() io.WriteCloser {
+	return &lineWriter{shipper: s}
+}
+
+// Flush reports all the pending records.
+func (s *shipper) Flush(ctx context.Context) error {
+	return s.report(ctx)
+}
+
+// Close reports all the pending records and stops the shipper.
+func (s *shipper) Close(ctx context.Context) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return types.ErrAlreadyTerminated()
+	}
+
+	s.closed = true
+	s.mutex.Unlock()
+
+	close(s.stop)
+	<-s.stopped
+
+	return s.report(ctx)
+}
+
+// enqueue adds a record without trailing '\n' into pending.
+func (s *shipper) enqueue(record []byte) {
+	record = bytes.Clone(record)
+
+	s.mutex.Lock()
+	if s.closed || len(s.pending) >= s.conf.MaxPendingRecords {
+		s.mutex.Unlock()
+		s.fallback([][]byte{record})
+
+		return
+	}
+
+	s.pending = append(s.pending, record)
+	full := len(s.pending) >= s.conf.MaxRecords
+	s.mutex.Unlock()
+
+	if full {
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *shipper) holdLinger() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.conf.Linger)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+
+		case <-ticker.C:
+		case <-s.full:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.conf.ReportTimeout)
+		// the failed records are already written to fallback, nowhere else to report the error.
+		_ = s.report(ctx)
+		cancel()
+	}
+}
+
+// report reports the pending records in frames, once a report failed, the rest records go to fallback.
+func (s *shipper) report(ctx context.Context) error {
+	s.reportMutex.Lock()
+	defer s.reportMutex.Unlock()
+
+	s.mutex.Lock()
+	records := s.pending
+	s.pending = nil
+	s.mutex.Unlock()
+
+	var (
+		frame []byte
+		count int
+		err   error
+	)
+
+	for len(records) > 0 {
+		record := records[0]
+
+		if len(record) > s.conf.MaxBytes {
+			s.fallback(records[:1])
+			records = records[1:]
+
+			err = errors.Join(err, types.ErrMessageTooLarge())
+
+			continue
+		}
+
+		if count > 0 && (count >= s.conf.MaxRecords || len(frame)+1+len(record) > s.conf.MaxBytes) {
+			if reportErr := s.reporter.ReportData(ctx, s.conf.DataID, frame); reportErr != nil && !types.IsQueued(reportErr) {
+				s.fallback(append([][]byte{frame}, records...))
+				return errors.Join(err, reportErr)
+			}
+
+			frame, count = nil, 0
+		}
+
+		if count > 0 {
+			frame = append(frame, '\n')
+		}
+
+		frame = append(frame, record...)
+		count++
+		records = records[1:]
+	}
+
+	if count > 0 {
+		if reportErr := s.reporter.ReportData(ctx, s.conf.DataID, frame); reportErr != nil && !types.IsQueued(reportErr) {
+			s.fallback([][]byte{frame})
+			return errors.Join(err, reportErr)
+		}
+	}
+
+	return err
+}
+
+// fallback writes the records into the local sink, the failure is ignored as the last resort.
+func (s *shipper) fallback(records [][]byte) {
+	if s.conf.Fallback == nil {
+		return
+	}
+
+	s.fallbackMutex.Lock()
+	defer s.fallbackMutex.Unlock()
+
+	for _, record := range records {
+		_, _ = s.conf.Fallback.Write(append(record, '\n'))
+	}
+}
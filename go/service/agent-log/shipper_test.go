@@ -0,0 +1,247 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// fakeReporter records the reported frames, it fails the reports from failAt, 1-based, with err.
+type fakeReporter struct {
+	frames []string
+	failAt int
+	err    error
+}
+
+func (r *fakeReporter) ReportData(_ context.Context, _ uint32, content []byte) error {
+	if r.failAt > 0 && len(r.frames)+1 >= r.failAt {
+		return r.err
+	}
+
+	r.frames = append(r.frames, string(content))
+
+	return nil
+}
+
+// newTestShipper creates a shipper without the linger reporting, the records are reported by report only.
+func newTestShipper(reporter Reporter, fallback *bytes.Buffer, modify func(conf *Config)) *shipper {
+	conf := NewDefaultConfig()
+	conf.DataID = 1
+	conf.Fallback = fallback
+
+	if modify != nil {
+		modify(conf)
+	}
+
+	return &shipper{conf: conf, reporter: reporter, full: make(chan struct{}, 1)}
+}
+
+func pendingRecords(s *shipper) []string {
+	records := make([]string, 0, len(s.pending))
+	for _, record := range s.pending {
+		records = append(records, string(record))
+	}
+
+	return records
+}
+
+func TestHandlerLevel(t *testing.T) {
+	s := newTestShipper(&fakeReporter{}, nil, func(conf *Config) { conf.Level = slog.LevelWarn })
+
+	logger := slog.New(s.Handler()).With("plugin", "test")
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn", "key", "value")
+	logger.Error("error")
+
+	if len(s.pending) != 2 {
+		t.Fatalf("%d records pending, want 2: %v", len(s.pending), pendingRecords(s))
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(s.pending[0], &record); err != nil {
+		t.Fatalf("unmarshal record failed: %v", err)
+	}
+
+	if record["msg"] != "warn" || record["key"] != "value" || record["plugin"] != "test" {
+		t.Fatalf("unexpected record: %v", record)
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	tests := []struct {
+		name      string
+		writes    []string
+		lineLevel func(line []byte) slog.Level
+		records   []string
+	}{
+		{name: "lines in one write", writes: []string{"a\nb\n"}, records: []string{"a", "b"}},
+		{name: "line across writes", writes: []string{"a", "b", "c\nd"}, records: []string{"abc", "d"}},
+		{name: "crlf and empty lines", writes: []string{"a\r\n\n\r\nb\n"}, records: []string{"a", "b"}},
+		{name: "overlong line", writes: []string{"0123", "456789", "ab\n"}, records: []string{"0123456789", "ab"}},
+		{
+			name:   "line level",
+			writes: []string{"DEBUG a\nINFO b\nERROR c\n"},
+			lineLevel: func(line []byte) slog.Level {
+				if bytes.HasPrefix(line, []byte("DEBUG")) {
+					return slog.LevelDebug
+				}
+
+				return slog.LevelInfo
+			},
+			records: []string{"INFO b", "ERROR c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestShipper(&fakeReporter{}, nil, func(conf *Config) {
+				conf.MaxBytes = 8
+				conf.LineLevel = tt.lineLevel
+			})
+
+			w := s.Writer()
+			for _, write := range tt.writes {
+				if n, err := w.Write([]byte(write)); n != len(write) || err != nil {
+					t.Fatalf("write returns %d, %v", n, err)
+				}
+			}
+
+			// closing ships the last line without '\n'.
+			if err := w.Close(); err != nil {
+				t.Fatalf("close failed: %v", err)
+			}
+
+			if records := pendingRecords(s); !reflect.DeepEqual(records, tt.records) {
+				t.Fatalf("shipped records %q, want %q", records, tt.records)
+			}
+		})
+	}
+}
+
+func TestShipperReport(t *testing.T) {
+	errReport := errors.New("report failed")
+
+	tests := []struct {
+		name     string
+		records  []string
+		reporter *fakeReporter
+		frames   []string
+		fallback []string
+		err      error
+	}{
+		{
+			name:     "frames by max records",
+			records:  []string{"r1", "r2", "r3"},
+			reporter: &fakeReporter{},
+			frames:   []string{"r1\nr2", "r3"},
+		},
+		{
+			name:     "frames by max bytes",
+			records:  []string{"r1", "r2-long8", "r3"},
+			reporter: &fakeReporter{},
+			frames:   []string{"r1", "r2-long8", "r3"},
+		},
+		{
+			name:     "record over max bytes",
+			records:  []string{"r1", "r2-overlong", "r3"},
+			reporter: &fakeReporter{},
+			frames:   []string{"r1\nr3"},
+			fallback: []string{"r2-overlong"},
+			err:      types.ErrMessageTooLarge(),
+		},
+		{
+			name:     "report failed",
+			records:  []string{"r1", "r2", "r3", "r4", "r5"},
+			reporter: &fakeReporter{failAt: 2, err: errReport},
+			frames:   []string{"r1\nr2"},
+			fallback: []string{"r3", "r4", "r5"},
+			err:      errReport,
+		},
+		{
+			name:     "last report failed",
+			records:  []string{"r1", "r2", "r3"},
+			reporter: &fakeReporter{failAt: 2, err: errReport},
+			frames:   []string{"r1\nr2"},
+			fallback: []string{"r3"},
+			err:      errReport,
+		},
+		{
+			name:     "report queued",
+			records:  []string{"r1"},
+			reporter: &fakeReporter{failAt: 1, err: &types.AgentStatusError{Queued: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback := new(bytes.Buffer)
+			s := newTestShipper(tt.reporter, fallback, func(conf *Config) {
+				conf.MaxRecords = 2
+				conf.MaxBytes = 10
+			})
+
+			for _, record := range tt.records {
+				s.enqueue([]byte(record))
+			}
+
+			err := s.report(context.Background())
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("report returns %v, want %v", err, tt.err)
+			}
+
+			if !reflect.DeepEqual(tt.reporter.frames, tt.frames) {
+				t.Fatalf("reported frames %q, want %q", tt.reporter.frames, tt.frames)
+			}
+
+			// the failed frame is written to fallback as it is, every record in a line.
+			lines := strings.Fields(fallback.String())
+			if !reflect.DeepEqual(lines, tt.fallback) && len(lines)+len(tt.fallback) > 0 {
+				t.Fatalf("fallback records %q, want %q", lines, tt.fallback)
+			}
+
+			if len(s.pending) != 0 {
+				t.Fatalf("%d records pending after report", len(s.pending))
+			}
+		})
+	}
+}
+
+func TestShipperClosed(t *testing.T) {
+	fallback := new(bytes.Buffer)
+	s := newTestShipper(&fakeReporter{}, fallback, func(conf *Config) { conf.MaxPendingRecords = 1 })
+
+	// the records over max pending records go to fallback instead of blocking.
+	s.enqueue([]byte("r1"))
+	s.enqueue([]byte("r2"))
+
+	s.closed = true
+	s.enqueue([]byte("r3"))
+
+	if records, lines := pendingRecords(s), fallback.String(); !reflect.DeepEqual(records, []string{"r1"}) ||
+		lines != "r2\nr3\n" {
+		t.Fatalf("pending records %q and fallback %q, want [r1] and r2, r3", records, lines)
+	}
+}
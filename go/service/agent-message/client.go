@@ -19,29 +19,51 @@ package agentmessage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
+	"slices"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/TencentBlueKing/bk-gse-sdk/go/internal"
 	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/agent"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/chunk"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/envelope"
 	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
 )
 
 // Client provides all handling methods in agent message.
 type Client interface {
 	// Launch starts connecting to an agent and holding, wait until it's connected or the context is done.
+	// it could be called again after the client is terminated.
 	Launch(ctx context.Context) error
 
 	// Terminate terminates the connection holding from an agent.
+	// it stops accepting new messages from server, and waits for the running callbacks and in-flight sends
+	// until the context is done, then closes the connection.
+	// the abandoned works are reported in *types.TerminateError.
 	Terminate(ctx context.Context) error
 
 	// SendMessage sends a message respond to server though agent.
+	// with agent status policy configured, it waits, queues or fails with *types.AgentStatusError while agent is
+	// unavailable, the queued message is sent when agent is running again and types.IsQueued returns true.
 	SendMessage(ctx context.Context, messageID string, content []byte) error
 
+	// AgentStatus returns the agent status in the newest keepalive response, types.AgentStatusUnknown before
+	// the first one or after the connection is lost.
+	AgentStatus() types.AgentStatus
+
 	// GetAgentInfo returns agent info.
 	GetAgentInfo() (types.AgentInfo, error)
+
+	// Codec returns the codec of typed message content.
+	Codec() types.Codec
+
+	// State returns the lifecycle state of client.
+	State() types.ClientState
+
+	// Stats returns the delivery statistics of client, it's cheap enough to call every second.
+	Stats() types.Stats
 }
 
 // Callback defines a callback function for client to call when receive a message.
@@ -59,10 +81,25 @@ func New(opts ...OptionFn) (Client, error) {
 		return nil, err
 	}
 
+	assembler := chunk.NewAssembler(conf.ChunkTimeout, conf.MaxChunkedMessageBytes,
+		conf.MaxPendingChunked, conf.MaxPendingChunkedBytes)
+
 	c := &client{
-		conf: conf,
-		done: make(chan struct{}),
+		conf:      conf,
+		assembler: assembler,
+		gate:      internal.NewStatusGate(conf.AgentStatusPolicy, conf.AgentStatusQueueSize),
+		stats:     internal.NewStats(),
 	}
+
+	if conf.Envelope.Enabled() {
+		env, err := envelope.New(conf.Envelope)
+		if err != nil {
+			return nil, err
+		}
+
+		c.envelope = env
+	}
+
 	c.client = agent.New(agent.Config{
 		DomainSocketPath:    conf.DomainSocketPath,
 		LocalSocketPort:     conf.LocalSocketPort,
@@ -73,7 +110,9 @@ func New(opts ...OptionFn) (Client, error) {
 			c.handleReceive(header, content)
 		},
 		RecvHeader: agent.NewMessageHeader(),
-		Logger:     conf.Logger,
+		// the agent status is of the lost or terminated connection, it's unknown until the next keepalive response.
+		DisconnectCallback: c.gate.Reset,
+		Logger:             conf.Logger,
 	})
 
 	return c, nil
@@ -82,12 +121,28 @@ func New(opts ...OptionFn) (Client, error) {
 type client struct {
 	conf *Config
 
-	authorized atomic.Bool
+	// handling counts the running callbacks.
+	handling internal.InFlight
 
-	done chan struct{}
+	// stopKeepalive is closed to stop the keepalive of current launch, keepalive waits for its exiting.
+	stopKeepalive chan struct{}
+	keepalive     sync.WaitGroup
+	launchMutex   sync.Mutex
 
 	client agent.Client
 
+	// assembler reassembles the chunked messages from server.
+	assembler *chunk.Assembler
+
+	// envelope seals the outgoing messages and opens the incoming messages, nil if it's disabled.
+	envelope *envelope.Envelope
+
+	// gate admits the sending according to the agent status from keepalive response.
+	gate *internal.StatusGate
+
+	// stats collects the delivery statistics.
+	stats *internal.Stats
+
 	// agentInfo describes the agent newest info from keepalive response.
 	agentInfo types.AgentInfo
 	mutex     sync.RWMutex
@@ -95,34 +150,119 @@ type client struct {
 
 // Launch starts connecting to an agent and holding, wait until it's connected or the context is done.
 func (c *client) Launch(ctx context.Context) error {
+	c.launchMutex.Lock()
+	defer c.launchMutex.Unlock()
+
 	if err := c.client.Launch(ctx); err != nil {
 		return err
 	}
 
-	go c.holdKeepalive() // nolint:contextcheck
+	c.stopKeepalive = make(chan struct{})
+	c.keepalive.Add(1)
+
+	go func(stop <-chan struct{}) {
+		defer c.keepalive.Done()
+		c.holdKeepalive(stop) // nolint:contextcheck
+	}(c.stopKeepalive)
 
 	return nil
 }
 
 // Terminate terminates the connection holding from an agent.
 func (c *client) Terminate(ctx context.Context) error {
-	if err := c.client.Terminate(ctx); err != nil {
+	c.launchMutex.Lock()
+	defer c.launchMutex.Unlock()
+
+	// stop accepting messages from server.
+	if err := c.client.Drain(); err != nil {
 		return err
 	}
 
-	c.done <- struct{}{}
+	abandonedHandlers := c.handling.Wait(ctx)
 
-	return nil
+	close(c.stopKeepalive)
+	c.stopKeepalive = nil
+
+	summary := new(types.TerminateError)
+	if err := c.client.Terminate(ctx); err != nil && !errors.As(err, &summary) {
+		return err
+	}
+
+	c.keepalive.Wait()
+
+	if dropped := c.gate.Clear(); dropped > 0 {
+		c.conf.Logger.Warn("terminated with %d queued messages dropped", dropped)
+	}
+
+	if abandonedHandlers == 0 && summary.AbandonedSends == 0 {
+		return nil
+	}
+
+	summary.AbandonedHandlers = abandonedHandlers
+	summary.Err = ctx.Err()
+
+	c.conf.Logger.Warn("terminated with %d handlers and %d sends abandoned",
+		summary.AbandonedHandlers, summary.AbandonedSends)
+
+	return summary
 }
 
 // SendMessage sends a message respond to server though agent.
 func (c *client) SendMessage(ctx context.Context, messageID string, content []byte) error {
-	return c.sendMessage(ctx, messageID, content)
+	var deferred func()
+	if c.conf.AgentStatusPolicy == types.AgentStatusPolicyQueue {
+		// the caller may reuse the content after it's queued.
+		queued := slices.Clone(content)
+		deferred = func() {
+			if err := c.send(context.Background(), messageID, queued); err != nil {
+				c.conf.Logger.Warn("send queued message failed. message-id: %s, err: %v", messageID, err)
+			}
+		}
+	}
+
+	if err := c.gate.Admit(ctx, deferred); err != nil {
+		if types.IsQueued(err) {
+			c.conf.Logger.Debug("send message queued. message-id: %s, err: %v", messageID, err)
+			return err
+		}
+
+		c.conf.Logger.Warn("send message deferred or rejected. message-id: %s, err: %v", messageID, err)
+		c.stats.Sent(0, err)
+
+		return err
+	}
+
+	return c.send(ctx, messageID, content)
+}
+
+// AgentStatus returns the agent status in the newest keepalive response.
+func (c *client) AgentStatus() types.AgentStatus {
+	return c.gate.Status()
+}
+
+// send seals and sends the message, the large one is sent in chunks.
+func (c *client) send(ctx context.Context, messageID string, content []byte) error {
+	if c.envelope != nil {
+		sealed, err := c.envelope.Seal(messageID, content)
+		if err != nil {
+			c.conf.Logger.Error("seal message failed. message-id: %s, err: %v", messageID, err)
+			c.stats.Sent(0, err)
+			return err
+		}
+
+		content = sealed
+	}
+
+	if c.conf.ChunkSizeBytes == 0 || len(content) <= int(c.conf.ChunkSizeBytes) {
+		return c.sendMessage(ctx, messageID, content)
+	}
+
+	return c.sendChunkedMessage(ctx, messageID, content)
 }
 
 // GetAgentInfo returns agent info.
 func (c *client) GetAgentInfo() (types.AgentInfo, error) {
-	if !c.authorized.Load() {
+	if c.client.State() != types.ClientStateAuthorized {
 		return types.AgentInfo{}, types.ErrNotAthorized()
 	}
 
@@ -132,6 +272,21 @@ func (c *client) GetAgentInfo() (types.AgentInfo, error) {
 	return c.agentInfo, nil
 }
 
+// Stats returns the delivery statistics of client.
+func (c *client) Stats() types.Stats {
+	return c.stats.Snapshot(c.client.Reconnects())
+}
+
+// Codec returns the codec of typed message content.
+func (c *client) Codec() types.Codec {
+	return c.conf.Codec
+}
+
+// State returns the lifecycle state of client.
+func (c *client) State() types.ClientState {
+	return c.client.State()
+}
+
 func (c *client) sendMessage(ctx context.Context, messageID string, content []byte) error {
 	request := agent.SendMessage{
 		Name:         c.conf.PluginName,
@@ -157,7 +312,10 @@ func (c *client) sendMessage(ctx context.Context, messageID string, content []by
 	copy(buffer, info)
 	copy(buffer[len(info):], content)
 
-	if err = c.client.SendMessage(ctx, header, buffer); err != nil {
+	err = c.client.SendMessage(ctx, header, buffer)
+	c.stats.Sent(int(header.Length), err)
+
+	if err != nil {
 		c.conf.Logger.Error("send message to agent failed. message-id: %s, err: %v", messageID, err)
 		return err
 	}
@@ -167,32 +325,62 @@ func (c *client) sendMessage(ctx context.Context, messageID string, content []by
 	return nil
 }
 
+func (c *client) sendChunkedMessage(ctx context.Context, messageID string, content []byte) error {
+	frames, err := chunk.Split(messageID, content, int(c.conf.ChunkSizeBytes))
+	if err != nil {
+		c.conf.Logger.Error("split chunked message failed. message-id: %s, err: %v", messageID, err)
+		return err
+	}
+
+	total := uint32(len(frames))
+	for i, frame := range frames {
+		if err = c.sendMessage(ctx, chunk.MessageID(messageID, uint32(i), total), frame); err != nil {
+			return err
+		}
+	}
+
+	c.conf.Logger.Debug("sent chunked message to agent. message-id: %s, size: %d, chunks: %d",
+		messageID, len(content), total)
+
+	return nil
+}
+
 func (c *client) handleReceive(recvHeader agent.IHeader, content []byte) {
 	header, ok := recvHeader.(*agent.MessageHeader)
 	if !ok {
 		c.conf.Logger.Warn("received unknown header: %v", recvHeader)
+		c.stats.Received(0, types.ErrInvalidProtocol())
+
 		return
 	}
 
+	// every frame is recorded once after it's handled.
+	size := int(header.TotalLength())
+
 	switch header.ProtoType {
 	case agent.ProtoTypeKeepaliveResp:
-		go c.handleKeepaliveResp(header, content)
+		go func() {
+			c.stats.Received(size, c.handleKeepaliveResp(header, content))
+		}()
 
 	case agent.ProtoTypeDispatchMessage:
-		c.handleDispatchMessage(header, content)
+		c.stats.Received(size, c.handleDispatchMessage(header, content))
 
 	default:
 		c.conf.Logger.Warn("received unknown message type: 0x%x", header.ProtoType)
+		c.stats.Received(size, types.ErrInvalidProtocol())
 	}
 }
 
-func (c *client) handleKeepaliveResp(_ *agent.MessageHeader, content []byte) {
+func (c *client) handleKeepaliveResp(_ *agent.MessageHeader, content []byte) error {
 	var resp agent.KeepaliveResp
 	if err := json.Unmarshal(content, &resp); err != nil {
 		c.conf.Logger.Warn("unmarshal keepalive response failed: %v", err)
-		return
+		return errors.Join(types.ErrInvalidProtocol(), err)
 	}
 
+	c.stats.KeepaliveReceived(time.Now())
+
 	c.mutex.Lock()
 	c.agentInfo = types.AgentInfo{
 		AgentSimpleInfo: types.AgentSimpleInfo{
@@ -206,67 +394,133 @@ func (c *client) handleKeepaliveResp(_ *agent.MessageHeader, content []byte) {
 	}
 	c.mutex.Unlock()
 
-	c.authorized.Store(true)
+	c.gate.Update(types.AgentStatus(resp.StatusCode))
+	c.client.Authorize()
 
 	c.conf.Logger.Debug("received keepalive response: %v", resp)
+
+	return nil
 }
 
-func (c *client) handleDispatchMessage(header *agent.MessageHeader, content []byte) {
+// handleDispatchMessage handles the dispatch message frame, returns the error if it's invalid or dropped
+// in reassembly or envelope verification.
+func (c *client) handleDispatchMessage(header *agent.MessageHeader, content []byte) error {
+	c.handling.Add()
+	defer c.handling.Done()
+
+	if state := c.client.State(); state == types.ClientStateDraining || state == types.ClientStateStopped {
+		c.conf.Logger.Warn("drop dispatch message while %s: %v", state.String(), header)
+		return nil
+	}
+
 	infoLen := header.Reserved0
 	dataLen := header.Reserved1
 
 	if infoLen == 0 || infoLen+dataLen != uint32(len(content)) || infoLen+dataLen+header.HeaderLength() != header.Length {
 		c.conf.Logger.Error("recv message from agent with invalid length: %v", header)
-		return
+		return types.ErrInvalidProtocol()
 	}
 
 	var resp agent.RecvMessage
 	if err := json.Unmarshal(content[:infoLen], &resp); err != nil {
 		c.conf.Logger.Error("unmarshal recv message failed: %v", err)
-		return
+		return errors.Join(types.ErrInvalidProtocol(), err)
 	}
 
 	c.conf.Logger.Debug("received dispatch message: %v", resp)
 
-	c.conf.RecvCallback(resp.MessageID, content[infoLen:])
+	data := content[infoLen:]
+	// the chunk frames are always reassembled, no matter whether chunked transfer is enabled in sending.
+	if !chunk.IsFrame(data) {
+		return c.dispatch(resp.MessageID, data)
+	}
+
+	chunkHeader, payload, err := c.assembler.Add("", data)
+	if errors.Is(err, types.ErrChunkIncomplete()) {
+		return nil
+	}
+
+	if err != nil {
+		c.conf.Logger.Error("reassemble chunked message failed. message-id: %s, err: %v", resp.MessageID, err)
+		return err
+	}
+
+	c.conf.Logger.Debug("received chunked message. message-id: %s, size: %d, chunks: %d",
+		chunkHeader.MessageID, len(payload), chunkHeader.Total)
+
+	return c.dispatch(chunkHeader.MessageID, payload)
 }
 
-func (c *client) holdKeepalive() {
+// dispatch opens the envelope of message and calls the callback, the rejected message never reaches the callback.
+func (c *client) dispatch(messageID string, content []byte) error {
+	if c.envelope != nil {
+		opened, err := c.envelope.Open(messageID, content)
+		if err != nil {
+			c.conf.Logger.Error("open message envelope failed, drop it. message-id: %s, err: %v", messageID, err)
+			return err
+		}
+
+		content = opened
+	}
+
+	c.conf.RecvCallback(messageID, content)
+
+	return nil
+}
+
+func (c *client) holdKeepalive(stop <-chan struct{}) {
 	c.conf.Logger.Info("start sending keepalive with interval %s", c.conf.KeepaliveInterval.String())
 
-	for ; ; time.Sleep(c.conf.KeepaliveInterval) {
+	ticker := time.NewTicker(c.conf.KeepaliveInterval)
+	defer ticker.Stop()
+
+	for {
+		if dropped := c.assembler.Expire(time.Now()); dropped > 0 {
+			c.conf.Logger.Warn("dropped %d chunked messages for timeout", dropped)
+		}
+
+		c.sendKeepalive()
+
 		select {
-		case <-c.done:
+		case <-stop:
 			c.conf.Logger.Info("stop sending keepalive")
 			return
 
-		default:
-			request := agent.KeepaliveReq{
-				PluginName: c.conf.PluginName,
-				Version:    c.conf.PluginVersion,
-				Pid:        os.Getpid(),
-				StatusCode: 0,
-				Status:     "ok",
-				Remark:     "",
-			}
+		case <-ticker.C:
+		}
+	}
+}
 
-			buf, err := json.Marshal(&request)
-			if err != nil {
-				c.conf.Logger.Warn("marshal keepalive request failed: %v", err)
-				continue
-			}
+func (c *client) sendKeepalive() {
+	request := agent.KeepaliveReq{
+		PluginName: c.conf.PluginName,
+		Version:    c.conf.PluginVersion,
+		Pid:        os.Getpid(),
+		StatusCode: 0,
+		Status:     "ok",
+		Remark:     "",
+	}
 
-			header := agent.NewMessageHeader()
-			header.ProtoType = agent.ProtoTypeKeepaliveReq
-			header.Sequence = internal.GenerateSequence()
-			header.Length = uint32(len(buf)) + header.HeaderLength()
+	buf, err := json.Marshal(&request)
+	if err != nil {
+		c.conf.Logger.Warn("marshal keepalive request failed: %v", err)
+		return
+	}
 
-			if err = c.client.SendMessage(context.Background(), header, buf); err != nil {
-				c.conf.Logger.Warn("send keepalive request failed: %v", err)
-				continue
-			}
+	header := agent.NewMessageHeader()
+	header.ProtoType = agent.ProtoTypeKeepaliveReq
+	header.Sequence = internal.GenerateSequence()
+	header.Length = uint32(len(buf)) + header.HeaderLength()
 
-			c.conf.Logger.Debug("send keepalive request succeed")
-		}
+	err = c.client.SendMessage(context.Background(), header, buf)
+	c.stats.Sent(int(header.Length), err)
+
+	if err != nil {
+		c.conf.Logger.Warn("send keepalive request failed: %v", err)
+		return
 	}
+
+	c.stats.KeepaliveSent(time.Now())
+
+	c.conf.Logger.Debug("send keepalive request succeed")
 }
@@ -0,0 +1,101 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentmessage
+
+import (
+	"testing"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/agent"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// dispatchFrame creates the header and content of a dispatch message frame.
+func dispatchFrame(info, data string) (*agent.MessageHeader, []byte) {
+	header := agent.NewMessageHeader()
+	header.ProtoType = agent.ProtoTypeDispatchMessage
+	header.Reserved0 = uint32(len(info))
+	header.Reserved1 = uint32(len(data))
+	header.Length = header.HeaderLength() + uint32(len(info)+len(data))
+
+	return header, []byte(info + data)
+}
+
+func TestHandleReceiveStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(header *agent.MessageHeader)
+		info     string
+		frames   uint64
+		errors   uint64
+		received []string
+	}{
+		{name: "dispatch message", info: `{"message_id":"m1"}`, frames: 1, received: []string{"m1:hello"}},
+		{name: "invalid length", info: `{"message_id":"m1"}`, modify: func(h *agent.MessageHeader) { h.Length++ }, errors: 1},
+		{name: "invalid message info", info: `{`, errors: 1},
+		{
+			name:   "unknown message type",
+			info:   `{"message_id":"m1"}`,
+			modify: func(h *agent.MessageHeader) { h.ProtoType = 0x9999 },
+			errors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received []string
+
+			c, err := New(
+				WithDomainSocketPath("/tmp/agent.sock"),
+				WithPluginName("plugin"),
+				WithPluginVersion("1.0.0"),
+				WithLogger(types.NewEmptyLogger()),
+				WithRecvCallback(func(messageID string, content []byte) {
+					received = append(received, messageID+":"+string(content))
+				}),
+			)
+			if err != nil {
+				t.Fatalf("new client failed: %v", err)
+			}
+
+			header, content := dispatchFrame(tt.info, "hello")
+			if tt.modify != nil {
+				tt.modify(header)
+			}
+
+			c.(*client).handleReceive(header, content)
+
+			// every frame is recorded once, as a frame succeed or an error.
+			recv := c.Stats().Recv
+
+			var errs uint64
+			for _, count := range recv.Errors {
+				errs += count
+			}
+
+			if recv.Frames != tt.frames || errs != tt.errors {
+				t.Fatalf("recorded %d frames and %d errors, want %d and %d", recv.Frames, errs, tt.frames, tt.errors)
+			}
+
+			if tt.frames != 0 && recv.Bytes != uint64(header.Length) {
+				t.Fatalf("recorded %d bytes, want %d", recv.Bytes, header.Length)
+			}
+
+			if len(received) != len(tt.received) || (len(received) != 0 && received[0] != tt.received[0]) {
+				t.Fatalf("received messages %v, want %v", received, tt.received)
+			}
+		})
+	}
+}
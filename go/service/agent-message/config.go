@@ -19,20 +19,29 @@ import (
 	"errors"
 	"time"
 
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/chunk"
 	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
 )
 
 // NewDefaultConfig creates a default configuration for agent-message service.
 func NewDefaultConfig() *Config {
 	return &Config{
-		DomainSocketPath:    "",
-		LocalSocketPort:     0,
-		PluginName:          "",
-		ReconnectInterval:   defaultReconnectInterval,
-		KeepaliveInterval:   defaultKeepaliveInterval,
-		MaxMessageSizeBytes: defaultMaxMessageSizeBytes,
-		RecvCallback:        func(string, []byte) {},
-		Logger:              types.NewDefaultLogger(defaultLoggerLevel),
+		DomainSocketPath:       "",
+		LocalSocketPort:        0,
+		PluginName:             "",
+		ReconnectInterval:      defaultReconnectInterval,
+		KeepaliveInterval:      defaultKeepaliveInterval,
+		MaxMessageSizeBytes:    defaultMaxMessageSizeBytes,
+		ChunkSizeBytes:         0,
+		ChunkTimeout:           defaultChunkTimeout,
+		MaxChunkedMessageBytes: defaultMaxChunkedMessageBytes,
+		MaxPendingChunked:      defaultMaxPendingChunked,
+		MaxPendingChunkedBytes: defaultMaxPendingChunkedBytes,
+		Codec:                  types.NewJSONCodec(),
+		RecvCallback:           func(string, []byte) {},
+		AgentStatusPolicy:      types.AgentStatusPolicyIgnore,
+		AgentStatusQueueSize:   defaultAgentStatusQueueSize,
+		Logger:                 types.NewDefaultLogger(defaultLoggerLevel),
 	}
 }
 
@@ -41,6 +50,16 @@ const (
 	defaultKeepaliveInterval   = 3 * time.Second
 	defaultMaxMessageSizeBytes = 1024 * 1024 * 10
 	defaultLoggerLevel         = 1 // INFO
+
+	defaultAgentStatusQueueSize = 1000
+
+	defaultChunkTimeout           = 60 * time.Second
+	defaultMaxChunkedMessageBytes = 1024 * 1024 * 256
+	defaultMaxPendingChunked      = 64
+	defaultMaxPendingChunkedBytes = 1024 * 1024 * 512
+
+	// chunkReservedBytes reserves the space for message info and chunk header in every chunk message.
+	chunkReservedBytes = 4096
 )
 
 // Config defines the configuration for agent-message service.
@@ -67,9 +86,37 @@ type Config struct {
 	// MaxMessageSizeBytes describes the max message size in bytes.
 	MaxMessageSizeBytes uint32
 
+	// ChunkSizeBytes describes the max content size in bytes of every chunk in chunked transfer.
+	// the message larger than it will be split into chunks, 0 means chunked transfer is disabled.
+	// the chunk is base64 encoded in frame, so the frame is about 4/3 of it plus the chunk header.
+	ChunkSizeBytes uint32
+
+	// ChunkTimeout describes how long to wait for all chunks of a chunked message before dropping it.
+	ChunkTimeout time.Duration
+
+	// MaxChunkedMessageBytes describes the max size in bytes of a reassembled chunked message.
+	MaxChunkedMessageBytes uint64
+
+	// MaxPendingChunked and MaxPendingChunkedBytes describes the max number and total received bytes of
+	// the partial chunked messages, the oldest ones are dropped when over the limits.
+	MaxPendingChunked      int
+	MaxPendingChunkedBytes uint64
+
+	// Envelope describes the signing and encryption envelope of message content shared with server.
+	Envelope types.EnvelopeConfig
+
+	// Codec describes the codec of typed message content, default in json.
+	Codec types.Codec
+
 	// RecvCallback describes the callback function for agent message service to call when receive a message.
 	RecvCallback Callback
 
+	// AgentStatusPolicy describes how to send while the agent is busy, upgrading, stopping or damage.
+	AgentStatusPolicy types.AgentStatusPolicy
+
+	// AgentStatusQueueSize describes the max number of queued messages with types.AgentStatusPolicyQueue.
+	AgentStatusQueueSize int
+
 	// Logger describes the logger for this service.
 	Logger types.Logger
 }
@@ -92,10 +139,40 @@ func (c Config) Validate() error {
 		return errors.Join(types.ErrInvalidConfig(), errors.New("keepalive interval is 0"))
 	}
 
+	if c.ChunkSizeBytes != 0 &&
+		uint64(chunk.EncodedLen(int(c.ChunkSizeBytes)))+chunkReservedBytes > uint64(c.MaxMessageSizeBytes) {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("chunk size is over max message size"))
+	}
+
+	if c.ChunkTimeout == 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("chunk timeout is 0"))
+	}
+
+	if c.MaxPendingChunked <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("max pending chunked messages is 0"))
+	}
+
+	if c.MaxPendingChunkedBytes < c.MaxChunkedMessageBytes {
+		return errors.Join(types.ErrInvalidConfig(),
+			errors.New("max pending chunked bytes is under max chunked message size"))
+	}
+
+	if c.Codec == nil {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("codec is empty"))
+	}
+
 	if c.RecvCallback == nil {
 		return errors.Join(types.ErrInvalidConfig(), errors.New("recv callback function is empty"))
 	}
 
+	if c.AgentStatusPolicy < types.AgentStatusPolicyIgnore || c.AgentStatusPolicy > types.AgentStatusPolicyQueue {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("unknown agent status policy"))
+	}
+
+	if c.AgentStatusPolicy == types.AgentStatusPolicyQueue && c.AgentStatusQueueSize <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("agent status queue size is 0"))
+	}
+
 	if c.Logger == nil {
 		return errors.Join(types.ErrInvalidConfig(), errors.New("logger is empty"))
 	}
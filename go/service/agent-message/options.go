@@ -73,6 +73,45 @@ func WithMaxMessageSizeBytes(size uint32) OptionFn {
 	}
 }
 
+// WithChunkedTransfer enables the chunked transfer for the messages larger than chunkSizeBytes,
+// and drops the partial received chunked messages after timeout.
+func WithChunkedTransfer(chunkSizeBytes uint32, timeout time.Duration) OptionFn {
+	return func(c *Config) {
+		c.ChunkSizeBytes = chunkSizeBytes
+		c.ChunkTimeout = timeout
+	}
+}
+
+// WithMaxChunkedMessageBytes sets the max size in bytes of a reassembled chunked message.
+func WithMaxChunkedMessageBytes(size uint64) OptionFn {
+	return func(c *Config) {
+		c.MaxChunkedMessageBytes = size
+	}
+}
+
+// WithMaxPendingChunked sets the max number and total received bytes of the partial chunked messages,
+// the oldest ones are dropped when over the limits.
+func WithMaxPendingChunked(count int, size uint64) OptionFn {
+	return func(c *Config) {
+		c.MaxPendingChunked = count
+		c.MaxPendingChunkedBytes = size
+	}
+}
+
+// WithEnvelope enables signing and optionally encryption of the message content shared with server.
+func WithEnvelope(envelope types.EnvelopeConfig) OptionFn {
+	return func(c *Config) {
+		c.Envelope = envelope
+	}
+}
+
+// WithCodec sets the codec of typed message content.
+func WithCodec(codec types.Codec) OptionFn {
+	return func(c *Config) {
+		c.Codec = codec
+	}
+}
+
 // WithRecvCallback sets the callback function for receiving message.
 func WithRecvCallback(callback Callback) OptionFn {
 	return func(c *Config) {
@@ -80,6 +119,20 @@ func WithRecvCallback(callback Callback) OptionFn {
 	}
 }
 
+// WithAgentStatusPolicy sets how to send while the agent is busy, upgrading, stopping or damage.
+func WithAgentStatusPolicy(policy types.AgentStatusPolicy) OptionFn {
+	return func(c *Config) {
+		c.AgentStatusPolicy = policy
+	}
+}
+
+// WithAgentStatusQueueSize sets the max number of queued messages with types.AgentStatusPolicyQueue.
+func WithAgentStatusQueueSize(size int) OptionFn {
+	return func(c *Config) {
+		c.AgentStatusQueueSize = size
+	}
+}
+
 // WithLogger sets the logger.
 func WithLogger(logger types.Logger) OptionFn {
 	return func(c *Config) {
@@ -0,0 +1,72 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentmessage
+
+import (
+	"context"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// TypedCallback defines a callback function for typed message.
+type TypedCallback[T any] func(messageID string, value T)
+
+// ErrorCallback defines a callback function for the message failed in handling.
+type ErrorCallback func(messageID string, err error)
+
+// SendTyped encodes the value with the client codec and sends it to server.
+// the encoding failure returns *types.CodecError.
+func SendTyped[T any](ctx context.Context, c Client, messageID string, value T) error {
+	codec := c.Codec()
+
+	content, err := codec.Marshal(value)
+	if err != nil {
+		return &types.CodecError{
+			Codec:     codec.Name(),
+			Operation: types.CodecOperationEncode,
+			MessageID: messageID,
+			Err:       err,
+		}
+	}
+
+	return c.SendMessage(ctx, messageID, content)
+}
+
+// WithTypedRecvCallback sets the callback function for receiving message, which decodes the message content
+// into T with the client codec, see WithCodec, and calls the handler.
+// the message failed in decoding never reaches the handler, it's passed to onError as *types.CodecError.
+func WithTypedRecvCallback[T any](handler TypedCallback[T], onError ErrorCallback) OptionFn {
+	return func(c *Config) {
+		// the codec is read on receiving, so it's the client codec no matter the order of options.
+		c.RecvCallback = func(messageID string, content []byte) {
+			var value T
+			if err := c.Codec.Unmarshal(content, &value); err != nil {
+				if onError != nil {
+					onError(messageID, &types.CodecError{
+						Codec:     c.Codec.Name(),
+						Operation: types.CodecOperationDecode,
+						MessageID: messageID,
+						Err:       err,
+					})
+				}
+
+				return
+			}
+
+			handler(messageID, value)
+		}
+	}
+}
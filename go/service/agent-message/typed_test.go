@@ -0,0 +1,122 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentmessage
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// gobCodec is a codec other than json, to tell the client codec is used.
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := gob.NewEncoder(buf).Encode(v)
+
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// fakeSender is a Client which records the sent messages.
+type fakeSender struct {
+	Client
+
+	codec    types.Codec
+	contents [][]byte
+}
+
+func (c *fakeSender) Codec() types.Codec {
+	return c.codec
+}
+
+func (c *fakeSender) SendMessage(_ context.Context, _ string, content []byte) error {
+	c.contents = append(c.contents, content)
+	return nil
+}
+
+type typedQuery struct {
+	Command string
+	Args    []string
+}
+
+func TestTypedRoundTrip(t *testing.T) {
+	for _, codec := range []types.Codec{types.NewJSONCodec(), gobCodec{}} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			sender := &fakeSender{codec: codec}
+			query := typedQuery{Command: "uptime", Args: []string{"-p", "蓝鲸"}}
+
+			if err := SendTyped(context.Background(), sender, "msg", query); err != nil {
+				t.Fatalf("send typed failed: %v", err)
+			}
+
+			var received []typedQuery
+
+			conf := NewDefaultConfig()
+			// the codec set after the callback is still used.
+			WithTypedRecvCallback(func(_ string, value typedQuery) {
+				received = append(received, value)
+			}, func(messageID string, err error) {
+				t.Fatalf("decode message %s failed: %v", messageID, err)
+			})(conf)
+			WithCodec(codec)(conf)
+
+			conf.RecvCallback("msg", sender.contents[0])
+
+			if !reflect.DeepEqual(received, []typedQuery{query}) {
+				t.Fatalf("received %+v, want %+v", received, query)
+			}
+		})
+	}
+}
+
+func TestTypedCodecError(t *testing.T) {
+	sender := &fakeSender{codec: types.NewJSONCodec()}
+
+	var codecErr *types.CodecError
+	if err := SendTyped(context.Background(), sender, "msg", func() {}); !errors.As(err, &codecErr) ||
+		codecErr.Operation != types.CodecOperationEncode || codecErr.Codec != "json" {
+		t.Fatalf("send unencodable value returns %v, want encode *types.CodecError", err)
+	}
+
+	conf := NewDefaultConfig()
+	WithCodec(gobCodec{})(conf)
+
+	var failed error
+	WithTypedRecvCallback(func(string, typedQuery) {
+		t.Fatal("message failed in decoding reaches handler")
+	}, func(_ string, err error) {
+		failed = err
+	})(conf)
+
+	conf.RecvCallback("msg", []byte(`{"Command":"uptime"}`))
+
+	if !errors.As(failed, &codecErr) || codecErr.Operation != types.CodecOperationDecode || codecErr.Codec != "gob" ||
+		codecErr.MessageID != "msg" {
+		t.Fatalf("decode invalid content fails with %v, want decode *types.CodecError of gob", failed)
+	}
+}
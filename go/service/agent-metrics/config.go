@@ -0,0 +1,87 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentmetrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// NewDefaultConfig creates a default configuration for metrics flusher.
+func NewDefaultConfig() *Config {
+	return &Config{
+		DataID:        0,
+		Interval:      defaultInterval,
+		ReportTimeout: defaultReportTimeout,
+		MaxBytes:      defaultMaxBytes,
+		Logger:        types.NewDefaultLogger(defaultLoggerLevel),
+	}
+}
+
+const (
+	defaultInterval      = 60 * time.Second
+	defaultReportTimeout = 10 * time.Second
+	defaultMaxBytes      = 1024 * 1024
+	defaultLoggerLevel   = 1 // INFO
+)
+
+// Config defines the configuration for metrics flusher.
+type Config struct {
+	// DataID describes the data-id which the snapshots are reported to.
+	DataID uint32
+
+	// Interval describes the interval of reporting snapshots.
+	Interval time.Duration
+
+	// ReportTimeout describes the timeout of reporting a snapshot on interval.
+	ReportTimeout time.Duration
+
+	// MaxBytes describes the max size in bytes of a single report, larger snapshot is split into several reports.
+	MaxBytes int
+
+	// Labels describes the common labels of all points.
+	Labels Labels
+
+	// Logger describes the logger for metrics flusher.
+	Logger types.Logger
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	if c.DataID == 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("data id is 0"))
+	}
+
+	if c.Interval <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("interval is 0"))
+	}
+
+	if c.ReportTimeout <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("report timeout is 0"))
+	}
+
+	if c.MaxBytes <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("max bytes is 0"))
+	}
+
+	if c.Logger == nil {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("logger is empty"))
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+// Package agentmetrics provides a lightweight metrics registry which reports snapshots through agent data report.
+package agentmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// Flusher periodically reports the snapshots of registry to the data-id.
+type Flusher interface {
+	// Flush reports the current snapshot.
+	Flush(ctx context.Context) error
+
+	// Close stops the periodical reporting and reports the final snapshot.
+	Close(ctx context.Context) error
+}
+
+// NewFlusher creates a new flusher which reports the snapshots of registry by reporter.
+func NewFlusher(registry *Registry, reporter Reporter, opts ...OptionFn) (Flusher, error) {
+	conf := NewDefaultConfig()
+
+	for _, opt := range opts {
+		opt(conf)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	f := &flusher{
+		conf:     conf,
+		registry: registry,
+		reporter: reporter,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	go f.holdInterval()
+
+	return f, nil
+}
+
+type flusher struct {
+	conf *Config
+
+	registry *Registry
+	reporter Reporter
+
+	closed bool
+	mutex  sync.Mutex
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// Flush reports the current snapshot.
+func (f *flusher) Flush(ctx context.Context) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.closed {
+		return types.ErrAlreadyTerminated()
+	}
+
+	return f.flush(ctx, time.Now())
+}
+
+// Close stops the periodical reporting and reports the final snapshot.
+func (f *flusher) Close(ctx context.Context) error {
+	f.mutex.Lock()
+	if f.closed {
+		f.mutex.Unlock()
+		return types.ErrAlreadyTerminated()
+	}
+
+	f.closed = true
+	f.mutex.Unlock()
+
+	close(f.stop)
+	<-f.stopped
+
+	return f.flush(ctx, time.Now())
+}
+
+func (f *flusher) holdInterval() {
+	defer close(f.stopped)
+
+	ticker := time.NewTicker(f.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+
+		case now := <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), f.conf.ReportTimeout)
+
+			f.mutex.Lock()
+			if err := f.flush(ctx, now); err != nil {
+				f.conf.Logger.Warn("report metrics snapshot to data-id %d failed: %v", f.conf.DataID, err)
+			}
+			f.mutex.Unlock()
+
+			cancel()
+		}
+	}
+}
+
+// flush reports the snapshot in one or more reports which fit in MaxBytes.
+func (f *flusher) flush(ctx context.Context, now time.Time) error {
+	points := f.registry.Snapshot()
+	if len(points) == 0 {
+		return nil
+	}
+
+	reports, err := f.encode(now, points)
+
+	for _, report := range reports {
+		if reportErr := f.reporter.ReportData(ctx, f.conf.DataID, report); reportErr != nil && !types.IsQueued(reportErr) {
+			return errors.Join(err, reportErr)
+		}
+	}
+
+	f.conf.Logger.Debug("reported %d metric points to data-id %d in %d reports", len(points), f.conf.DataID, len(reports))
+
+	return err
+}
+
+// encode encodes the points into reports, the point which could not be encoded or fit in a single report is skipped.
+func (f *flusher) encode(now time.Time, points []Point) ([][]byte, error) {
+	report := Report{
+		Version:   FormatVersion,
+		Timestamp: now.UnixMilli(),
+		Labels:    f.conf.Labels,
+	}
+
+	// overhead is the size of the report without points, every point adds its size and a comma.
+	empty, err := json.Marshal(&report)
+	if err != nil {
+		return nil, err
+	}
+
+	overhead := len(empty) - len("null") + len("[]")
+
+	var (
+		reports [][]byte
+		skipErr error
+		batch   []Point
+		size    = overhead
+	)
+
+	appendReport := func() error {
+		report.Points = batch
+		data, err := json.Marshal(&report)
+		if err != nil {
+			return err
+		}
+
+		reports = append(reports, data)
+		batch, size = nil, overhead
+
+		return nil
+	}
+
+	for _, point := range points {
+		// the point with NaN or infinite values could not be encoded in json, it's skipped alone.
+		data, err := json.Marshal(&point)
+		if err != nil {
+			f.conf.Logger.Warn("skip metric %s point which could not be encoded: %v", point.Name, err)
+			skipErr = errors.Join(skipErr, fmt.Errorf("encode metric %s point failed: %w", point.Name, err))
+			continue
+		}
+
+		if overhead+len(data) > f.conf.MaxBytes {
+			skipErr = errors.Join(skipErr, types.ErrMessageTooLarge(),
+				fmt.Errorf("metric %s point size %d over max bytes %d", point.Name, len(data), f.conf.MaxBytes))
+			continue
+		}
+
+		if len(batch) > 0 && size+1+len(data) > f.conf.MaxBytes {
+			if err := appendReport(); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(batch) > 0 {
+			size++
+		}
+
+		batch = append(batch, point)
+		size += len(data)
+	}
+
+	if len(batch) > 0 {
+		if err := appendReport(); err != nil {
+			return nil, err
+		}
+	}
+
+	return reports, skipErr
+}
@@ -0,0 +1,115 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentmetrics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// newPoints creates the gauge points named from m000 in order.
+func newPoints(n int) []Point {
+	points := make([]Point, 0, n)
+	for i := 0; i < n; i++ {
+		value := float64(i)
+		points = append(points, Point{Name: fmt.Sprintf("m%03d", i), Type: TypeGauge, Value: &value})
+	}
+
+	return points
+}
+
+func TestFlusherEncode(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+
+	// size is the size of a report with the first n points.
+	size := func(n int) int {
+		data, _ := json.Marshal(&Report{Version: FormatVersion, Timestamp: now.UnixMilli(), Points: newPoints(n)})
+		return len(data)
+	}
+
+	// the NaN could not be encoded in json.
+	nan := math.NaN()
+
+	tests := []struct {
+		name     string
+		points   []Point
+		maxBytes int
+		reports  []int
+		err      error
+	}{
+		{name: "single report", points: newPoints(10), maxBytes: size(10), reports: []int{10}},
+		{name: "split at max bytes", points: newPoints(10), maxBytes: size(4), reports: []int{4, 4, 2}},
+		{name: "one point per report", points: newPoints(3), maxBytes: size(1), reports: []int{1, 1, 1}},
+		{name: "point over max bytes", points: newPoints(3), maxBytes: size(0), err: types.ErrMessageTooLarge()},
+		{
+			name:     "point not encodable",
+			points:   append(newPoints(2), Point{Name: "nan", Type: TypeGauge, Value: &nan}),
+			maxBytes: size(2) + 64,
+			reports:  []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewDefaultConfig()
+			conf.MaxBytes = tt.maxBytes
+			conf.Logger = types.NewEmptyLogger()
+
+			f := &flusher{conf: conf}
+
+			reports, err := f.encode(now, tt.points)
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("encode returns %v, want %v", err, tt.err)
+			}
+
+			if len(reports) != len(tt.reports) {
+				t.Fatalf("encoded into %d reports, want %d", len(reports), len(tt.reports))
+			}
+
+			next := 0
+			for i, data := range reports {
+				if len(data) > tt.maxBytes {
+					t.Fatalf("report %d of %d bytes over max bytes %d", i, len(data), tt.maxBytes)
+				}
+
+				var report Report
+				if err := json.Unmarshal(data, &report); err != nil {
+					t.Fatalf("unmarshal report %d failed: %v", i, err)
+				}
+
+				if len(report.Points) != tt.reports[i] || report.Timestamp != now.UnixMilli() {
+					t.Fatalf("report %d has %d points at %d, want %d", i, len(report.Points), report.Timestamp,
+						tt.reports[i])
+				}
+
+				// the points are kept in order across the reports.
+				for _, point := range report.Points {
+					if want := fmt.Sprintf("m%03d", next); point.Name != want {
+						t.Fatalf("report %d has point %s, want %s", i, point.Name, want)
+					}
+
+					next++
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentmetrics
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+)
+
+// Type describes the type of metric.
+type Type string
+
+const (
+	// TypeCounter is a monotonically increasing value.
+	TypeCounter Type = "counter"
+
+	// TypeGauge is a value that can go up and down.
+	TypeGauge Type = "gauge"
+
+	// TypeHistogram counts the observations in buckets.
+	TypeHistogram Type = "histogram"
+)
+
+// DefaultBuckets are the default histogram buckets, fit for the latency in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Labels describes the labels of metric.
+type Labels map[string]string
+
+type metric interface {
+	point() Point
+}
+
+// atomicFloat is a float64 which could be updated atomically.
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+func (f *atomicFloat) store(v float64) {
+	f.bits.Store(math.Float64bits(v))
+}
+
+func (f *atomicFloat) add(delta float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	name   string
+	labels Labels
+	value  atomicFloat
+}
+
+// Inc increases the counter by 1.
+func (c *Counter) Inc() {
+	c.value.add(1)
+}
+
+// Add increases the counter by delta, negative delta is ignored.
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		return
+	}
+
+	c.value.add(delta)
+}
+
+// Value returns the current value.
+func (c *Counter) Value() float64 {
+	return c.value.load()
+}
+
+func (c *Counter) point() Point {
+	value := c.value.load()
+	return Point{Name: c.name, Type: TypeCounter, Labels: c.labels, Value: &value}
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	name   string
+	labels Labels
+	value  atomicFloat
+}
+
+// Set sets the gauge to value.
+func (g *Gauge) Set(value float64) {
+	g.value.store(value)
+}
+
+// Add adds delta to the gauge, delta could be negative.
+func (g *Gauge) Add(delta float64) {
+	g.value.add(delta)
+}
+
+// Inc increases the gauge by 1.
+func (g *Gauge) Inc() {
+	g.value.add(1)
+}
+
+// Dec decreases the gauge by 1.
+func (g *Gauge) Dec() {
+	g.value.add(-1)
+}
+
+// Value returns the current value.
+func (g *Gauge) Value() float64 {
+	return g.value.load()
+}
+
+func (g *Gauge) point() Point {
+	value := g.value.load()
+	return Point{Name: g.name, Type: TypeGauge, Labels: g.labels, Value: &value}
+}
+
+// Histogram counts the observations in buckets.
+type Histogram struct {
+	name   string
+	labels Labels
+
+	// bounds are the sorted upper bounds of buckets.
+	bounds []float64
+
+	// counts are the observation counts of every bucket, the last one is for the observations over all bounds.
+	counts []atomic.Uint64
+	sum    atomicFloat
+}
+
+func newHistogram(name string, labels Labels, buckets []float64) *Histogram {
+	bounds := make([]float64, 0, len(buckets))
+	for _, b := range buckets {
+		if !math.IsInf(b, 0) && !math.IsNaN(b) {
+			bounds = append(bounds, b)
+		}
+	}
+
+	sort.Float64s(bounds)
+
+	return &Histogram{
+		name:   name,
+		labels: labels,
+		bounds: bounds,
+		counts: make([]atomic.Uint64, len(bounds)+1),
+	}
+}
+
+// Observe adds an observation.
+func (h *Histogram) Observe(value float64) {
+	h.counts[sort.SearchFloat64s(h.bounds, value)].Add(1)
+	h.sum.add(value)
+}
+
+func (h *Histogram) point() Point {
+	buckets := make([]Bucket, 0, len(h.bounds))
+
+	cumulative := uint64(0)
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i].Load()
+		buckets = append(buckets, Bucket{UpperBound: bound, Count: cumulative})
+	}
+
+	count := cumulative + h.counts[len(h.bounds)].Load()
+	sum := h.sum.load()
+
+	return Point{Name: h.name, Type: TypeHistogram, Labels: h.labels, Count: &count, Sum: &sum, Buckets: buckets}
+}
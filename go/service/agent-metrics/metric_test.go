@@ -0,0 +1,84 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentmetrics
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	// the infinite bounds are dropped and the bounds are sorted.
+	h := newHistogram("latency", nil, []float64{1, math.Inf(1), 0.5, 2})
+
+	// the bounds are inclusive, the observation over all bounds only counts in count.
+	for _, v := range []float64{0.1, 0.5, 0.6, 1, 2, 2.5, 100} {
+		h.Observe(v)
+	}
+
+	point := h.point()
+
+	want := []Bucket{{UpperBound: 0.5, Count: 2}, {UpperBound: 1, Count: 4}, {UpperBound: 2, Count: 5}}
+	if !reflect.DeepEqual(point.Buckets, want) {
+		t.Fatalf("histogram buckets %v, want %v", point.Buckets, want)
+	}
+
+	if *point.Count != 7 || *point.Sum != 106.7 {
+		t.Fatalf("histogram count %d and sum %v, want 7 and 106.7", *point.Count, *point.Sum)
+	}
+
+	if point.Type != TypeHistogram || point.Value != nil {
+		t.Fatalf("unexpected histogram point: %+v", point)
+	}
+}
+
+func TestCounter(t *testing.T) {
+	c := &Counter{name: "requests"}
+
+	c.Inc()
+	c.Add(2.5)
+	// the counter never goes down.
+	c.Add(-10)
+
+	if value := c.Value(); value != 3.5 {
+		t.Fatalf("counter value %v, want 3.5", value)
+	}
+
+	if point := c.point(); point.Type != TypeCounter || *point.Value != 3.5 || point.Count != nil {
+		t.Fatalf("unexpected counter point: %+v", point)
+	}
+}
+
+func TestGauge(t *testing.T) {
+	g := &Gauge{name: "connections"}
+
+	g.Set(10)
+	g.Inc()
+	g.Dec()
+	g.Dec()
+	g.Add(-4.5)
+
+	if value := g.Value(); value != 4.5 {
+		t.Fatalf("gauge value %v, want 4.5", value)
+	}
+
+	g.Set(-1)
+
+	if point := g.point(); point.Type != TypeGauge || *point.Value != -1 {
+		t.Fatalf("unexpected gauge point: %+v", point)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentmetrics
+
+import (
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// OptionFn defines the function type for setting options.
+type OptionFn func(*Config)
+
+// WithDataID sets the data-id which the snapshots are reported to.
+func WithDataID(dataID uint32) OptionFn {
+	return func(c *Config) {
+		c.DataID = dataID
+	}
+}
+
+// WithInterval sets the interval of reporting snapshots.
+func WithInterval(interval time.Duration) OptionFn {
+	return func(c *Config) {
+		c.Interval = interval
+	}
+}
+
+// WithReportTimeout sets the timeout of reporting a snapshot on interval.
+func WithReportTimeout(timeout time.Duration) OptionFn {
+	return func(c *Config) {
+		c.ReportTimeout = timeout
+	}
+}
+
+// WithMaxBytes sets the max size in bytes of a single report.
+func WithMaxBytes(size int) OptionFn {
+	return func(c *Config) {
+		c.MaxBytes = size
+	}
+}
+
+// WithLabels sets the common labels of all points.
+func WithLabels(labels Labels) OptionFn {
+	return func(c *Config) {
+		c.Labels = labels
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(logger types.Logger) OptionFn {
+	return func(c *Config) {
+		c.Logger = logger
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentmetrics
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry holds the metrics, the same name and labels always refer to the same metric.
+type Registry struct {
+	metrics map[string]metric
+	types   map[string]Type
+	mutex   sync.RWMutex
+}
+
+// NewRegistry creates a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]metric),
+		types:   make(map[string]Type),
+	}
+}
+
+// Counter returns the counter of name and labels, it's created if not exists.
+func (r *Registry) Counter(name string, labels Labels) (*Counter, error) {
+	m, err := r.getOrCreate(name, TypeCounter, labels, func(labels Labels) metric {
+		return &Counter{name: name, labels: labels}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m.(*Counter), nil
+}
+
+// Gauge returns the gauge of name and labels, it's created if not exists.
+func (r *Registry) Gauge(name string, labels Labels) (*Gauge, error) {
+	m, err := r.getOrCreate(name, TypeGauge, labels, func(labels Labels) metric {
+		return &Gauge{name: name, labels: labels}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m.(*Gauge), nil
+}
+
+// Histogram returns the histogram of name and labels, it's created with the buckets if not exists.
+// nil buckets means DefaultBuckets, the buckets are ignored if the histogram already exists.
+func (r *Registry) Histogram(name string, labels Labels, buckets []float64) (*Histogram, error) {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+
+	m, err := r.getOrCreate(name, TypeHistogram, labels, func(labels Labels) metric {
+		return newHistogram(name, labels, buckets)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m.(*Histogram), nil
+}
+
+// Snapshot returns the current points of all metrics, sorted by name and labels.
+func (r *Registry) Snapshot() []Point {
+	r.mutex.RLock()
+	keys := make([]string, 0, len(r.metrics))
+	for key := range r.metrics {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	metrics := make([]metric, 0, len(keys))
+	for _, key := range keys {
+		metrics = append(metrics, r.metrics[key])
+	}
+	r.mutex.RUnlock()
+
+	points := make([]Point, 0, len(metrics))
+	for _, m := range metrics {
+		points = append(points, m.point())
+	}
+
+	return points
+}
+
+func (r *Registry) getOrCreate(name string, typ Type, labels Labels, create func(Labels) metric) (metric, error) {
+	if name == "" {
+		return nil, errors.New("metric name is empty")
+	}
+
+	key := metricKey(name, labels)
+
+	r.mutex.RLock()
+	m, ok := r.metrics[key]
+	r.mutex.RUnlock()
+
+	if ok {
+		if r.typeOf(name) != typ {
+			return nil, fmt.Errorf("metric %s is already registered as %s", name, r.typeOf(name))
+		}
+
+		return m, nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if registered, ok := r.types[name]; ok && registered != typ {
+		return nil, fmt.Errorf("metric %s is already registered as %s", name, registered)
+	}
+
+	if m, ok := r.metrics[key]; ok {
+		return m, nil
+	}
+
+	m = create(maps.Clone(labels))
+	r.metrics[key] = m
+	r.types[name] = typ
+
+	return m, nil
+}
+
+func (r *Registry) typeOf(name string) Type {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.types[name]
+}
+
+// metricKey returns the unique key of name and labels, labels are sorted by name and quoted,
+// so the label names and values containing the separators could not collide.
+func metricKey(name string, labels Labels) string {
+	names := make([]string, 0, len(labels))
+	for n := range labels {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	var key strings.Builder
+	key.WriteString(name)
+
+	for _, n := range names {
+		key.WriteByte(0)
+		key.WriteString(strconv.Quote(n))
+		key.WriteByte('=')
+		key.WriteString(strconv.Quote(labels[n]))
+	}
+
+	return key.String()
+}
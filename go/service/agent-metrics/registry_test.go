@@ -0,0 +1,84 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentmetrics
+
+import (
+	"testing"
+)
+
+func TestMetricKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   Labels
+		metric string
+		same   bool
+	}{
+		{name: "label order", a: Labels{"a": "1", "b": "2"}, b: Labels{"b": "2", "a": "1"}, same: true},
+		{name: "nil and empty labels", a: nil, b: Labels{}, same: true},
+		{name: "different value", a: Labels{"a": "1"}, b: Labels{"a": "2"}},
+		{name: "different name", a: Labels{"a": "1"}, b: Labels{"b": "1"}},
+		{name: "separator in value", a: Labels{"a": "1", "b": "2"}, b: Labels{"a": "1\x00b=2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := metricKey("metric", tt.a) == metricKey("metric", tt.b); same != tt.same {
+				t.Fatalf("keys of %v and %v are same %v, want %v", tt.a, tt.b, same, tt.same)
+			}
+		})
+	}
+}
+
+func TestRegistryGetOrCreate(t *testing.T) {
+	r := NewRegistry()
+
+	labels := Labels{"route": "/ping"}
+
+	c, err := r.Counter("requests", labels)
+	if err != nil {
+		t.Fatalf("create counter failed: %v", err)
+	}
+
+	// the labels are copied, changing them later creates no new metric.
+	labels["route"] = "/pong"
+
+	same, err := r.Counter("requests", Labels{"route": "/ping"})
+	if err != nil || same != c {
+		t.Fatalf("get counter returns %p, %v, want %p", same, err, c)
+	}
+
+	if _, err := r.Gauge("requests", nil); err == nil {
+		t.Fatal("gauge of registered counter name is created")
+	}
+
+	if _, err := r.Histogram("requests", Labels{"route": "/other"}, nil); err == nil {
+		t.Fatal("histogram of registered counter name is created")
+	}
+
+	if _, err := r.Counter("", nil); err == nil {
+		t.Fatal("counter of empty name is created")
+	}
+
+	if _, err := r.Gauge("connections", nil); err != nil {
+		t.Fatalf("create gauge failed: %v", err)
+	}
+
+	// the snapshot is sorted by name and labels.
+	points := r.Snapshot()
+	if len(points) != 2 || points[0].Name != "connections" || points[1].Labels["route"] != "/ping" {
+		t.Fatalf("unexpected snapshot: %+v", points)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentmetrics
+
+import (
+	agentreport "github.com/TencentBlueKing/bk-gse-sdk/go/service/agent-report"
+)
+
+// FormatVersion is the version of the reported json format, see Report.
+const FormatVersion = 1
+
+// Reporter reports the data to a data-id, see agentreport.Reporter.
+type Reporter = agentreport.Reporter
+
+// Report describes the json format of every reported snapshot.
+// a large snapshot is split into several reports which have the same timestamp.
+type Report struct {
+	// Version is the version of the format, see FormatVersion.
+	Version int `json:"version"`
+
+	// Timestamp is the time in unix milliseconds when the snapshot is taken.
+	Timestamp int64 `json:"timestamp"`
+
+	// Labels are the common labels of all points in the report.
+	Labels Labels `json:"labels,omitempty"`
+
+	// Points are the points of metrics.
+	Points []Point `json:"points"`
+}
+
+// Point describes the value of a metric in a snapshot.
+type Point struct {
+	Name   string `json:"name"`
+	Type   Type   `json:"type"`
+	Labels Labels `json:"labels,omitempty"`
+
+	// Value is the value of counter and gauge.
+	Value *float64 `json:"value,omitempty"`
+
+	// Count and Sum are the observation count and sum of histogram.
+	Count *uint64  `json:"count,omitempty"`
+	Sum   *float64 `json:"sum,omitempty"`
+
+	// Buckets are the cumulative buckets of histogram, the +Inf bucket is omitted as it equals to Count.
+	Buckets []Bucket `json:"buckets,omitempty"`
+}
+
+// Bucket describes a cumulative histogram bucket.
+type Bucket struct {
+	// UpperBound is the inclusive upper bound of the bucket.
+	UpperBound float64 `json:"le"`
+
+	// Count is the number of observations less than or equal to UpperBound.
+	Count uint64 `json:"count"`
+}
@@ -0,0 +1,395 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentreport
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// BatchReporter accumulates the records per data-id and reports them in batch frames.
+type BatchReporter interface {
+	// ReportData adds a record into the batch of data-id, the batch is reported when it's full.
+	ReportData(ctx context.Context, dataID uint32, content []byte) error
+
+	// Flush reports all the pending batches.
+	Flush(ctx context.Context) error
+
+	// Close stops the linger flushing and reports all the pending batches,
+	// the pending batches are abandoned if the context is done before the linger flushing stops.
+	Close(ctx context.Context) error
+}
+
+// NewBatchReporter creates a new batch reporter on the client.
+func NewBatchReporter(client Client, opts ...BatchOptionFn) (BatchReporter, error) {
+	conf := NewDefaultBatchConfig()
+
+	for _, opt := range opts {
+		opt(conf)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	limit := maxBatchBytes(client)
+	if conf.MaxBytes == 0 {
+		conf.MaxBytes = limit
+	}
+
+	if limit == 0 || conf.MaxBytes > limit {
+		return nil, errors.Join(types.ErrInvalidConfig(),
+			fmt.Errorf("batch max bytes %d over the limit %d of client max message size", conf.MaxBytes, limit))
+	}
+
+	r := &batchReporter{
+		conf:    conf,
+		client:  client,
+		batches: make(map[uint32]*batch),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go r.holdLinger()
+
+	return r, nil
+}
+
+type batchReporter struct {
+	conf *BatchConfig
+
+	client Client
+
+	// batches are the pending batches by data-id, the frames are taken out under mutex and reported after.
+	batches map[uint32]*batch
+	closed  bool
+	mutex   sync.Mutex
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+type batch struct {
+	records [][]byte
+
+	// size is the size in bytes of the batch frame.
+	size uint32
+
+	// firstAt is the time of the first record added.
+	firstAt time.Time
+
+	// last is closed after the latest frame taken from the batch is reported, nil if none taken,
+	// it's protected by the mutex of reporter. the frames are reported in the order of taking.
+	last chan struct{}
+}
+
+// frame is a batch frame taken from the batch of data-id and waiting for reporting.
+type frame struct {
+	dataID  uint32
+	records int
+	data    []byte
+
+	// prev is closed after the previous frame of the same data-id is reported, nil if none.
+	// done is closed after the frame is reported or dropped.
+	prev chan struct{}
+	done chan struct{}
+}
+
+const (
+	lenRecordPrefix = 4
+
+	// minLingerTick is the min period of checking the expired batches.
+	minLingerTick = time.Millisecond
+)
+
+// ReportData adds a record into the batch of data-id.
+func (r *batchReporter) ReportData(ctx context.Context, dataID uint32, content []byte) error {
+	if r.conf.Framing == FramingJSONArray && !json.Valid(content) {
+		return fmt.Errorf("record of data-id %d is not a valid json value", dataID)
+	}
+
+	if r.frameSize(nil, content) > r.conf.MaxBytes {
+		return errors.Join(types.ErrMessageTooLarge(),
+			fmt.Errorf("record of data-id %d size %d over batch max bytes %d", dataID, len(content), r.conf.MaxBytes))
+	}
+
+	frames, err := r.add(dataID, content)
+	if err != nil {
+		return err
+	}
+
+	return r.send(ctx, frames)
+}
+
+// add adds a record into the batch of data-id, returns the frames to report.
+func (r *batchReporter) add(dataID uint32, content []byte) ([]*frame, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return nil, types.ErrAlreadyTerminated()
+	}
+
+	b, ok := r.batches[dataID]
+	if !ok {
+		b = new(batch)
+		r.batches[dataID] = b
+	}
+
+	var frames []*frame
+
+	// take first if the record could not fit in current batch.
+	if len(b.records) > 0 && r.frameSize(b, content) > r.conf.MaxBytes {
+		frames = append(frames, r.take(dataID, b))
+	}
+
+	record := make([]byte, len(content))
+	copy(record, content)
+
+	if len(b.records) == 0 {
+		b.firstAt = time.Now()
+	}
+
+	b.size = r.frameSize(b, record)
+	b.records = append(b.records, record)
+
+	if len(b.records) >= r.conf.MaxRecords {
+		frames = append(frames, r.take(dataID, b))
+	}
+
+	return frames, nil
+}
+
+// Flush reports all the pending batches.
+func (r *batchReporter) Flush(ctx context.Context) error {
+	return r.send(ctx, r.takeAll())
+}
+
+// Close reports all the pending batches and stops the linger flushing.
+func (r *batchReporter) Close(ctx context.Context) error {
+	r.mutex.Lock()
+	if r.closed {
+		r.mutex.Unlock()
+		return types.ErrAlreadyTerminated()
+	}
+
+	r.closed = true
+	r.mutex.Unlock()
+
+	close(r.stop)
+
+	select {
+	case <-r.stopped:
+	case <-ctx.Done():
+		return errors.Join(types.ErrContextDone(), ctx.Err())
+	}
+
+	return r.send(ctx, r.takeAll())
+}
+
+func (r *batchReporter) holdLinger() {
+	defer close(r.stopped)
+
+	// the ticker period is clamped as time.NewTicker panics on non-positive period.
+	ticker := time.NewTicker(max(r.conf.Linger/2, minLingerTick)) // nolint:mnd
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+
+		case now := <-ticker.C:
+			r.flushExpired(now)
+		}
+	}
+}
+
+func (r *batchReporter) flushExpired(now time.Time) {
+	r.mutex.Lock()
+
+	var frames []*frame
+	for dataID, b := range r.batches {
+		if len(b.records) == 0 || now.Sub(b.firstAt) < r.conf.Linger {
+			continue
+		}
+
+		frames = append(frames, r.take(dataID, b))
+	}
+
+	r.mutex.Unlock()
+
+	if len(frames) == 0 {
+		return
+	}
+
+	// the linger flushing is bounded, Close waits for it.
+	ctx, cancel := context.WithTimeout(context.Background(), r.conf.FlushTimeout)
+	defer cancel()
+
+	if err := r.send(ctx, frames); err != nil {
+		r.conf.Logger.Warn("flush batches on linger failed: %v", err)
+	}
+}
+
+// takeAll takes the frames of all the pending batches.
+func (r *batchReporter) takeAll() []*frame {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var frames []*frame
+	for dataID, b := range r.batches {
+		if len(b.records) > 0 {
+			frames = append(frames, r.take(dataID, b))
+		}
+	}
+
+	return frames
+}
+
+// take takes the frame out of the batch, it must be called with mutex held and the batch is not empty.
+func (r *batchReporter) take(dataID uint32, b *batch) *frame {
+	f := &frame{
+		dataID:  dataID,
+		records: len(b.records),
+		data:    r.encode(b),
+		prev:    b.last,
+		done:    make(chan struct{}),
+	}
+
+	b.last = f.done
+	b.records = nil
+	b.size = 0
+
+	return f
+}
+
+// send reports the frames, the records are dropped even if reporting failed.
+func (r *batchReporter) send(ctx context.Context, frames []*frame) error {
+	var err error
+
+	for _, f := range frames {
+		err = errors.Join(err, r.sendFrame(ctx, f))
+	}
+
+	return err
+}
+
+// sendFrame reports the frame after the earlier frames of the same data-id are reported,
+// the frame is dropped if the context is done while waiting.
+func (r *batchReporter) sendFrame(ctx context.Context, f *frame) error {
+	if f.prev != nil {
+		select {
+		case <-f.prev:
+
+		case <-ctx.Done():
+			// the later frames still wait for the earlier ones to keep the order.
+			go func() {
+				<-f.prev
+				close(f.done)
+			}()
+
+			return fmt.Errorf("report batch of data-id %d with %d records failed: %w",
+				f.dataID, f.records, errors.Join(types.ErrContextDone(), ctx.Err()))
+		}
+	}
+
+	err := r.client.ReportData(ctx, f.dataID, f.data)
+	if types.IsQueued(err) {
+		err = nil
+	}
+
+	close(f.done)
+
+	if err != nil {
+		return fmt.Errorf("report batch of data-id %d with %d records failed: %w", f.dataID, f.records, err)
+	}
+
+	r.conf.Logger.Debug("reported batch of data-id %d with %d records in %d bytes", f.dataID, f.records, len(f.data))
+
+	return nil
+}
+
+// frameSize returns the size of batch frame after the record is added, b could be nil for an empty batch.
+func (r *batchReporter) frameSize(b *batch, record []byte) uint32 {
+	size, count := uint32(0), 0
+	if b != nil {
+		size, count = b.size, len(b.records)
+	}
+
+	recordLen := uint32(len(record))
+
+	switch r.conf.Framing {
+	case FramingJSONArray:
+		if count == 0 {
+			return recordLen + 2 // nolint:mnd
+		}
+
+		return size + 1 + recordLen
+
+	case FramingLengthPrefixed:
+		return size + lenRecordPrefix + recordLen
+
+	default:
+		if count == 0 {
+			return recordLen
+		}
+
+		return size + 1 + recordLen
+	}
+}
+
+func (r *batchReporter) encode(b *batch) []byte {
+	frame := make([]byte, 0, b.size)
+
+	switch r.conf.Framing {
+	case FramingJSONArray:
+		frame = append(frame, '[')
+		for i, record := range b.records {
+			if i > 0 {
+				frame = append(frame, ',')
+			}
+
+			frame = append(frame, record...)
+		}
+
+		frame = append(frame, ']')
+
+	case FramingLengthPrefixed:
+		for _, record := range b.records {
+			frame = binary.BigEndian.AppendUint32(frame, uint32(len(record)))
+			frame = append(frame, record...)
+		}
+
+	default:
+		for i, record := range b.records {
+			if i > 0 {
+				frame = append(frame, '\n')
+			}
+
+			frame = append(frame, record...)
+		}
+	}
+
+	return frame
+}
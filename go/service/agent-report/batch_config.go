@@ -0,0 +1,169 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentreport
+
+import (
+	"errors"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/agent"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// Framing describes how the records in a batch are joined into one frame.
+type Framing int
+
+const (
+	// FramingNewline joins the records with '\n'.
+	FramingNewline Framing = 0
+
+	// FramingJSONArray joins the records into a json array, every record must be a valid json value.
+	FramingJSONArray Framing = 1
+
+	// FramingLengthPrefixed prefixes every record with its length in 4 bytes big-endian.
+	FramingLengthPrefixed Framing = 2
+)
+
+// NewDefaultBatchConfig creates a default configuration for batch reporter.
+func NewDefaultBatchConfig() *BatchConfig {
+	return &BatchConfig{
+		Framing:      FramingNewline,
+		MaxRecords:   defaultBatchMaxRecords,
+		Linger:       defaultBatchLinger,
+		FlushTimeout: defaultBatchFlushTimeout,
+		Logger:       types.NewDefaultLogger(defaultLoggerLevel),
+	}
+}
+
+const (
+	defaultBatchMaxRecords   = 100
+	defaultBatchLinger       = 1 * time.Second
+	defaultBatchFlushTimeout = 5 * time.Second
+)
+
+// maxBatchBytes returns the max bytes of a batch frame which fits in a single message of the client,
+// the default max message size is used if the client does not tell its own.
+func maxBatchBytes(client Client) uint32 {
+	maxMessageSizeBytes := uint32(defaultMaxMessageSizeBytes)
+	if sizer, ok := client.(messageSizer); ok {
+		maxMessageSizeBytes = sizer.maxMessageSizeBytes()
+	}
+
+	headerLength := agent.NewDataUpHeader().HeaderLength()
+	if maxMessageSizeBytes <= headerLength {
+		return 0
+	}
+
+	return maxMessageSizeBytes - headerLength
+}
+
+// messageSizer is implemented by the client which tells its max message size.
+type messageSizer interface {
+	maxMessageSizeBytes() uint32
+}
+
+// BatchConfig defines the configuration for batch reporter.
+type BatchConfig struct {
+	// Framing describes how the records in a batch are joined into one frame.
+	Framing Framing
+
+	// MaxRecords describes the max number of records in a batch, the batch is flushed when it's reached.
+	MaxRecords int
+
+	// MaxBytes describes the max size in bytes of a batch frame, the batch is flushed before it's exceeded.
+	// it could not be larger than the max message size of the client minus the header,
+	// 0 means the max message size of the client minus the header.
+	MaxBytes uint32
+
+	// Linger describes how long a record could wait in batch before the batch is flushed.
+	Linger time.Duration
+
+	// FlushTimeout describes how long the batches flushed on linger could take to report.
+	FlushTimeout time.Duration
+
+	// Logger describes the logger for batch reporter.
+	Logger types.Logger
+}
+
+// Validate validates the configuration.
+func (c BatchConfig) Validate() error {
+	if c.Framing != FramingNewline && c.Framing != FramingJSONArray && c.Framing != FramingLengthPrefixed {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("unknown framing"))
+	}
+
+	if c.MaxRecords <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("batch max records is 0"))
+	}
+
+	if c.Linger <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("batch linger is 0"))
+	}
+
+	if c.FlushTimeout <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("batch flush timeout is 0"))
+	}
+
+	if c.Logger == nil {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("logger is empty"))
+	}
+
+	return nil
+}
+
+// BatchOptionFn defines the function type for setting batch options.
+type BatchOptionFn func(*BatchConfig)
+
+// WithBatchFraming sets how the records in a batch are joined.
+func WithBatchFraming(framing Framing) BatchOptionFn {
+	return func(c *BatchConfig) {
+		c.Framing = framing
+	}
+}
+
+// WithBatchMaxRecords sets the max number of records in a batch.
+func WithBatchMaxRecords(records int) BatchOptionFn {
+	return func(c *BatchConfig) {
+		c.MaxRecords = records
+	}
+}
+
+// WithBatchMaxBytes sets the max size in bytes of a batch frame.
+func WithBatchMaxBytes(size uint32) BatchOptionFn {
+	return func(c *BatchConfig) {
+		c.MaxBytes = size
+	}
+}
+
+// WithBatchLinger sets how long a record could wait in batch.
+func WithBatchLinger(linger time.Duration) BatchOptionFn {
+	return func(c *BatchConfig) {
+		c.Linger = linger
+	}
+}
+
+// WithBatchFlushTimeout sets how long the batches flushed on linger could take to report.
+func WithBatchFlushTimeout(timeout time.Duration) BatchOptionFn {
+	return func(c *BatchConfig) {
+		c.FlushTimeout = timeout
+	}
+}
+
+// WithBatchLogger sets the logger.
+func WithBatchLogger(logger types.Logger) BatchOptionFn {
+	return func(c *BatchConfig) {
+		c.Logger = logger
+	}
+}
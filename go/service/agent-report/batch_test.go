@@ -0,0 +1,305 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// fakeClient records the reported data, the other methods of Client are not implemented.
+type fakeClient struct {
+	Client
+
+	reports [][]byte
+	dataIDs []uint32
+	mutex   sync.Mutex
+
+	// block blocks the reporting after recorded until it's closed, nil for not blocking.
+	block chan struct{}
+}
+
+func (c *fakeClient) ReportData(_ context.Context, dataID uint32, content []byte) error {
+	c.mutex.Lock()
+	c.reports = append(c.reports, content)
+	c.dataIDs = append(c.dataIDs, dataID)
+	c.mutex.Unlock()
+
+	if c.block != nil {
+		<-c.block
+	}
+
+	return nil
+}
+
+func (c *fakeClient) reported() [][]byte {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([][]byte(nil), c.reports...)
+}
+
+func TestBatchReporterFraming(t *testing.T) {
+	lengthPrefixed := func(records ...string) []byte {
+		var frame []byte
+		for _, record := range records {
+			frame = binary.BigEndian.AppendUint32(frame, uint32(len(record)))
+			frame = append(frame, record...)
+		}
+
+		return frame
+	}
+
+	tests := []struct {
+		name    string
+		framing Framing
+		records []string
+		frames  [][]byte
+	}{
+		{
+			name:    "newline",
+			framing: FramingNewline,
+			records: []string{"a", "b", "c", "d", "e"},
+			frames:  [][]byte{[]byte("a\nb"), []byte("c\nd"), []byte("e")},
+		},
+		{
+			name:    "json array",
+			framing: FramingJSONArray,
+			records: []string{`{"a":1}`, `2`, `"c"`},
+			frames:  [][]byte{[]byte(`[{"a":1},2]`), []byte(`["c"]`)},
+		},
+		{
+			name:    "length prefixed",
+			framing: FramingLengthPrefixed,
+			records: []string{"a", "bc", "def"},
+			frames:  [][]byte{lengthPrefixed("a", "bc"), lengthPrefixed("def")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := new(fakeClient)
+
+			r, err := NewBatchReporter(client, WithBatchFraming(tt.framing), WithBatchMaxRecords(2),
+				WithBatchLinger(time.Hour))
+			if err != nil {
+				t.Fatalf("create batch reporter failed: %v", err)
+			}
+
+			for _, record := range tt.records {
+				if err := r.ReportData(context.Background(), 1, []byte(record)); err != nil {
+					t.Fatalf("report %s failed: %v", record, err)
+				}
+			}
+
+			if err := r.Close(context.Background()); err != nil {
+				t.Fatalf("close failed: %v", err)
+			}
+
+			reported := client.reported()
+			if len(reported) != len(tt.frames) {
+				t.Fatalf("reported %d frames, want %d", len(reported), len(tt.frames))
+			}
+
+			for i, frame := range tt.frames {
+				if !bytes.Equal(reported[i], frame) {
+					t.Fatalf("frame %d is %q, want %q", i, reported[i], frame)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchReporterMaxBytes(t *testing.T) {
+	client := new(fakeClient)
+
+	r, err := NewBatchReporter(client, WithBatchMaxBytes(10), WithBatchLinger(time.Hour))
+	if err != nil {
+		t.Fatalf("create batch reporter failed: %v", err)
+	}
+
+	// "aaaa\nbbbb" fits in 10 bytes, adding "\ncccc" does not.
+	for _, record := range []string{"aaaa", "bbbb", "cccc"} {
+		if err := r.ReportData(context.Background(), 1, []byte(record)); err != nil {
+			t.Fatalf("report %s failed: %v", record, err)
+		}
+	}
+
+	err = r.ReportData(context.Background(), 1, []byte("too large record"))
+	if !errors.Is(err, types.ErrMessageTooLarge()) {
+		t.Fatalf("report large record returns %v, want %v", err, types.ErrMessageTooLarge())
+	}
+
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	reported := client.reported()
+	if len(reported) != 2 || string(reported[0]) != "aaaa\nbbbb" || string(reported[1]) != "cccc" {
+		t.Fatalf("reported frames %q", reported)
+	}
+
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+}
+
+func TestBatchReporterInvalidJSON(t *testing.T) {
+	r, err := NewBatchReporter(new(fakeClient), WithBatchFraming(FramingJSONArray))
+	if err != nil {
+		t.Fatalf("create batch reporter failed: %v", err)
+	}
+	defer r.Close(context.Background())
+
+	if err := r.ReportData(context.Background(), 1, []byte("{")); err == nil {
+		t.Fatal("invalid json record is accepted")
+	}
+}
+
+func TestBatchReporterLinger(t *testing.T) {
+	client := new(fakeClient)
+
+	r, err := NewBatchReporter(client, WithBatchLinger(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("create batch reporter failed: %v", err)
+	}
+	defer r.Close(context.Background())
+
+	if err := r.ReportData(context.Background(), 1, []byte("a")); err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+
+	if err := r.ReportData(context.Background(), 2, []byte("b")); err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(client.reported()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if reported := client.reported(); len(reported) != 2 {
+		t.Fatalf("reported %d frames on linger, want 2", len(reported))
+	}
+}
+
+func TestBatchReporterClose(t *testing.T) {
+	client := new(fakeClient)
+
+	r, err := NewBatchReporter(client, WithBatchLinger(time.Hour))
+	if err != nil {
+		t.Fatalf("create batch reporter failed: %v", err)
+	}
+
+	if err := r.ReportData(context.Background(), 1, []byte("a")); err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	if reported := client.reported(); len(reported) != 1 || string(reported[0]) != "a" {
+		t.Fatalf("reported frames %q on close, want [a]", reported)
+	}
+
+	if err := r.ReportData(context.Background(), 1, []byte("b")); !errors.Is(err, types.ErrAlreadyTerminated()) {
+		t.Fatalf("report after close returns %v, want %v", err, types.ErrAlreadyTerminated())
+	}
+
+	if err := r.Close(context.Background()); !errors.Is(err, types.ErrAlreadyTerminated()) {
+		t.Fatalf("close again returns %v, want %v", err, types.ErrAlreadyTerminated())
+	}
+}
+
+func TestBatchReporterContextDone(t *testing.T) {
+	client := &fakeClient{block: make(chan struct{})}
+
+	r, err := NewBatchReporter(client, WithBatchMaxRecords(1), WithBatchLinger(time.Hour))
+	if err != nil {
+		t.Fatalf("create batch reporter failed: %v", err)
+	}
+
+	reportAsync := func(ctx context.Context, record string) <-chan error {
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- r.ReportData(ctx, 1, []byte(record))
+		}()
+
+		return errCh
+	}
+
+	first := reportAsync(context.Background(), "a")
+	for len(client.reported()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the frame waiting for the blocked one is dropped when the context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	select {
+	case err := <-reportAsync(ctx, "b"):
+		if !errors.Is(err, types.ErrContextDone()) {
+			t.Fatalf("report while waiting returns %v, want %v", err, types.ErrContextDone())
+		}
+
+	case <-time.After(time.Second):
+		t.Fatal("report is still waiting after the context is done")
+	}
+
+	// the later frame is still reported after the blocked one.
+	third := reportAsync(context.Background(), "c")
+	close(client.block)
+
+	for _, errCh := range []<-chan error{first, third} {
+		if err := <-errCh; err != nil {
+			t.Fatalf("report failed: %v", err)
+		}
+	}
+
+	if reported := client.reported(); len(reported) != 2 || string(reported[0]) != "a" || string(reported[1]) != "c" {
+		t.Fatalf("reported frames %q, want [a c]", reported)
+	}
+}
+
+func TestBatchReporterInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []BatchOptionFn
+	}{
+		{name: "unknown framing", opts: []BatchOptionFn{WithBatchFraming(Framing(3))}},
+		{name: "zero max records", opts: []BatchOptionFn{WithBatchMaxRecords(0)}},
+		{name: "max bytes over message size", opts: []BatchOptionFn{WithBatchMaxBytes(defaultMaxMessageSizeBytes)}},
+		{name: "zero linger", opts: []BatchOptionFn{WithBatchLinger(0)}},
+		{name: "zero flush timeout", opts: []BatchOptionFn{WithBatchFlushTimeout(0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewBatchReporter(new(fakeClient), tt.opts...); !errors.Is(err, types.ErrInvalidConfig()) {
+				t.Fatalf("create batch reporter returns %v, want %v", err, types.ErrInvalidConfig())
+			}
+		})
+	}
+}
@@ -17,28 +17,69 @@
 package agentreport
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal"
 	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/agent"
 	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
 )
 
+// Reporter reports the data to a data-id, Client and BatchReporter are both Reporter.
+// it's the reporter accepted by agent-metrics, agent-log and agent-tail.
+type Reporter interface {
+	ReportData(ctx context.Context, dataID uint32, content []byte) error
+}
+
 // Client provides all handling methods in agent data report.
 type Client interface {
 	// Launch starts connecting to an agent and holding, wait until it's connected or the context is done.
+	// it could be called again after the client is terminated.
 	Launch(ctx context.Context) error
 
 	// Terminate terminates the connection holding from an agent.
+	// it waits for the in-flight reports until the context is done, then closes the connection.
+	// the abandoned reports are reported in *types.TerminateError.
 	Terminate(ctx context.Context) error
 
 	// ReportData sends a data report to server though agent.
+	// with rate limits configured, it blocks or fails with types.ErrRateLimited according to the overflow policy.
+	// with agent status policy configured, it waits, queues or fails with *types.AgentStatusError while agent is
+	// unavailable, the queued report is reported later in order and types.IsQueued returns true for its error.
 	ReportData(ctx context.Context, dataID uint32, content []byte) error
 
-	// GetAgentInfo returns agent info.
+	// ReportDataAt sends a data report with the specified event time to server though agent.
+	// the time out of MaxClockSkew ahead or MaxEventAge behind the local clock fails with types.ErrInvalidTimestamp.
+	ReportDataAt(ctx context.Context, dataID uint32, ts time.Time, content []byte) error
+
+	// ReportEvent wraps the event in the standard envelope and sends it to server though agent.
+	// see EventEnvelope for the reported data format.
+	ReportEvent(ctx context.Context, dataID uint32, event Event) error
+
+	// GetAgentInfo returns agent info, it fails with types.ErrNotSynced before the first sync config response.
 	GetAgentInfo() (types.AgentSimpleInfo, error)
+
+	// GetSyncConfig returns a copy of the newest config synced from agent,
+	// it fails with types.ErrNotSynced before the first sync config response of current launch.
+	GetSyncConfig() (*SyncConfig, error)
+
+	// GetRateLimitStats returns the statistics of records over rate limits.
+	GetRateLimitStats() RateLimitStats
+
+	// State returns the lifecycle state of client.
+	State() types.ClientState
+
+	// Stats returns the delivery statistics of client, the reports are also counted by data-id.
+	// it's cheap enough to call every second.
+	Stats() types.Stats
 }
 
 // New creates a new agent-report client.
@@ -54,8 +95,10 @@ func New(opts ...OptionFn) (Client, error) {
 	}
 
 	c := &client{
-		conf: conf,
-		done: make(chan struct{}),
+		conf:    conf,
+		limiter: newRateLimiter(conf),
+		gate:    internal.NewStatusGate(conf.AgentStatusPolicy, conf.AgentStatusQueueSize),
+		stats:   internal.NewStats(),
 	}
 	c.client = agent.New(agent.Config{
 		DomainSocketPath:    conf.DomainSocketPath,
@@ -75,57 +118,213 @@ func New(opts ...OptionFn) (Client, error) {
 type client struct {
 	conf *Config
 
-	done chan struct{}
+	// stopKeepalive is closed to stop the keepalive of current launch, keepalive waits for its exiting.
+	stopKeepalive chan struct{}
+	keepalive     sync.WaitGroup
+	launchMutex   sync.Mutex
 
 	client agent.Client
 
-	// agentInfo describes the agent newest info from keepalive response.
-	agentInfo types.AgentSimpleInfo
-	mutex     sync.RWMutex
+	// limiter limits the reports by global and per data-id rate limits.
+	limiter *rateLimiter
+
+	// gate admits the reports according to the agent status polled from AgentStatusSource.
+	gate *internal.StatusGate
+
+	// stats collects the delivery statistics.
+	stats *internal.Stats
+
+	// syncConfig describes the newest config from keepalive(sync config) response, nil before the first one.
+	syncConfig *SyncConfig
+	mutex      sync.RWMutex
+
+	// changeMutex keeps the synced configs applied in order.
+	// received numbers the keepalive responses in receiving order, applied is the number of the newest one applied
+	// under changeMutex, the responses older than it are stale and dropped.
+	changeMutex sync.Mutex
+	received    atomic.Uint64
+	applied     uint64
+
+	// callbackMutex keeps the config changed callbacks in order, they are called out of changeMutex, so the
+	// callback could call Terminate. notified is the number of the newest response notified, the older are dropped.
+	callbackMutex sync.Mutex
+	notified      uint64
 }
 
 // Launch starts connecting to an agent and holding, wait until it's connected or the context is done.
 func (c *client) Launch(ctx context.Context) error {
+	c.launchMutex.Lock()
+	defer c.launchMutex.Unlock()
+
 	if err := c.client.Launch(ctx); err != nil {
 		return err
 	}
 
-	go c.holdKeepalive() // nolint:contextcheck
+	c.stopKeepalive = make(chan struct{})
+	c.keepalive.Add(1)
+
+	go func(stop <-chan struct{}) {
+		defer c.keepalive.Done()
+		c.holdKeepalive(stop) // nolint:contextcheck
+	}(c.stopKeepalive)
 
 	return nil
 }
 
 // Terminate terminates the connection holding from an agent.
 func (c *client) Terminate(ctx context.Context) error {
-	if err := c.client.Terminate(ctx); err != nil {
+	c.launchMutex.Lock()
+	defer c.launchMutex.Unlock()
+
+	if err := c.client.Drain(); err != nil {
 		return err
 	}
 
-	c.done <- struct{}{}
+	close(c.stopKeepalive)
+	c.stopKeepalive = nil
 
-	return nil
+	err := c.client.Terminate(ctx)
+	c.keepalive.Wait()
+
+	c.resetSyncConfig()
+
+	if dropped := c.gate.Clear(); dropped > 0 {
+		c.conf.Logger.Warn("drop %d queued reports while agent is unavailable", dropped)
+	}
+
+	return err
+}
+
+// State returns the lifecycle state of client.
+func (c *client) State() types.ClientState {
+	return c.client.State()
 }
 
 // ReportData sends a data report to server though agent.
 func (c *client) ReportData(ctx context.Context, dataID uint32, content []byte) error {
-	return c.reportData(ctx, dataID, content)
+	return c.reportData(ctx, dataID, time.Now(), content)
+}
+
+// ReportDataAt sends a data report with the specified event time to server though agent.
+func (c *client) ReportDataAt(ctx context.Context, dataID uint32, ts time.Time, content []byte) error {
+	if err := c.validateTimestamp(ts, time.Now()); err != nil {
+		c.stats.SentDataID(dataID, 0, err)
+		return err
+	}
+
+	return c.reportData(ctx, dataID, ts, content)
 }
 
 // GetAgentInfo returns agent info.
 func (c *client) GetAgentInfo() (types.AgentSimpleInfo, error) {
+	syncConfig, err := c.GetSyncConfig()
+	if err != nil {
+		return types.AgentSimpleInfo{}, err
+	}
+
+	return syncConfig.AgentInfo, nil
+}
+
+// maxMessageSizeBytes returns the max message size of client, see BatchReporter.
+func (c *client) maxMessageSizeBytes() uint32 {
+	return c.conf.MaxMessageSizeBytes
+}
+
+// GetSyncConfig returns the newest config synced from agent.
+func (c *client) GetSyncConfig() (*SyncConfig, error) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
-	return c.agentInfo, nil
+	if c.syncConfig == nil {
+		return nil, types.ErrNotSynced()
+	}
+
+	return c.syncConfig.clone(), nil
 }
 
-func (c *client) reportData(ctx context.Context, dataID uint32, content []byte) error {
+// resetSyncConfig forgets the config synced in the terminated launch,
+// the responses of it still in handling are dropped as stale.
+func (c *client) resetSyncConfig() {
+	c.changeMutex.Lock()
+	defer c.changeMutex.Unlock()
+
+	c.applied = c.received.Load()
+
+	c.mutex.Lock()
+	c.syncConfig = nil
+	c.mutex.Unlock()
+}
+
+// Stats returns the delivery statistics of client.
+func (c *client) Stats() types.Stats {
+	return c.stats.Snapshot(c.client.Reconnects())
+}
+
+// GetRateLimitStats returns the statistics of records over rate limits.
+func (c *client) GetRateLimitStats() RateLimitStats {
+	return c.limiter.stats()
+}
+
+// validateTimestamp validates the report time, which is encoded in uint32 unix seconds.
+func (c *client) validateTimestamp(ts, now time.Time) error {
+	if ts.Unix() < 0 || ts.Unix() > math.MaxUint32 {
+		return errors.Join(types.ErrInvalidTimestamp(), fmt.Errorf("time %s out of uint32 seconds", ts.String()))
+	}
+
+	if ts.Sub(now) > c.conf.MaxClockSkew {
+		return errors.Join(types.ErrInvalidTimestamp(),
+			fmt.Errorf("time %s is over %s ahead of now", ts.String(), c.conf.MaxClockSkew.String()))
+	}
+
+	if c.conf.MaxEventAge > 0 && now.Sub(ts) > c.conf.MaxEventAge {
+		return errors.Join(types.ErrInvalidTimestamp(),
+			fmt.Errorf("time %s is over %s behind now", ts.String(), c.conf.MaxEventAge.String()))
+	}
+
+	return nil
+}
+
+func (c *client) reportData(ctx context.Context, dataID uint32, ts time.Time, content []byte) error {
 	header := agent.NewDataUpHeader()
 	header.ProtoType = agent.ProtoTypeDataPluginReportReq
 	header.DataID = dataID
-	header.UTCTime = uint32(time.Now().UTC().UnixMilli())
+	header.UTCTime = uint32(ts.Unix())
 	header.BodyLength = uint32(len(content))
 
+	var deferred func()
+	if c.conf.AgentStatusPolicy == types.AgentStatusPolicyQueue {
+		content := slices.Clone(content)
+
+		deferred = func() {
+			// the caller is gone, the queued report is sent without its context.
+			err := c.sendReport(context.Background(), header, content)
+			c.stats.SentDataID(dataID, int(header.HeaderLength())+len(content), err)
+
+			if err != nil {
+				c.conf.Logger.Warn("report queued data of data-id %d failed: %v", dataID, err)
+			}
+		}
+	}
+
+	if err := c.gate.Admit(ctx, deferred); err != nil {
+		if !types.IsQueued(err) {
+			c.stats.SentDataID(dataID, 0, err)
+		}
+
+		return err
+	}
+
+	err := c.sendReport(ctx, header, content)
+	c.stats.SentDataID(dataID, int(header.HeaderLength())+len(content), err)
+
+	return err
+}
+
+func (c *client) sendReport(ctx context.Context, header *agent.DataUpHeader, content []byte) error {
+	if err := c.limiter.admit(ctx, header.DataID, len(content)); err != nil {
+		return err
+	}
+
 	return c.client.SendMessage(ctx, header, content)
 }
 
@@ -133,55 +332,139 @@ func (c *client) handleReceive(recvHeader agent.IHeader, content []byte) {
 	header, ok := recvHeader.(*agent.DataDownHeader)
 	if !ok {
 		c.conf.Logger.Warn("received unknown header: %v", recvHeader)
+		c.stats.Received(0, types.ErrInvalidProtocol())
+
 		return
 	}
 
+	// every frame is recorded once after it's handled.
+	size := int(header.TotalLength())
+
 	switch header.ProtoType {
 	case agent.ProtoTypeDataPluginSyncConfigResp:
-		go c.handleKeepaliveResp(header, content)
+		seq := c.received.Add(1)
+		go func() {
+			c.stats.Received(size, c.handleKeepaliveResp(seq, header, content))
+		}()
 
 	default:
 		c.conf.Logger.Warn("received unknown message type: 0x%x", header.ProtoType)
+		c.stats.Received(size, types.ErrInvalidProtocol())
 	}
 }
 
-func (c *client) handleKeepaliveResp(_ *agent.DataDownHeader, content []byte) {
+// handleKeepaliveResp handles the seq-th keepalive response received, returns the error if it's invalid.
+func (c *client) handleKeepaliveResp(seq uint64, _ *agent.DataDownHeader, content []byte) error {
 	var resp agent.DataPluginSyncConfigResp
 	if err := json.Unmarshal(content, &resp); err != nil {
 		c.conf.Logger.Warn("unmarshal keepalive(sync config) response failed: %v", err)
-		return
+		return errors.Join(types.ErrInvalidProtocol(), err)
 	}
 
-	c.mutex.Lock()
-	c.agentInfo = types.AgentSimpleInfo{
-		AgentID: resp.AgentID,
-		CloudID: resp.CloudID,
+	c.stats.KeepaliveReceived(time.Now())
+
+	raw := new(bytes.Buffer)
+	if err := json.Compact(raw, content); err != nil {
+		c.conf.Logger.Warn("compact keepalive(sync config) response failed: %v", err)
+		return errors.Join(types.ErrInvalidProtocol(), err)
+	}
+
+	current := &SyncConfig{
+		AgentInfo: types.AgentSimpleInfo{
+			AgentID: resp.AgentID,
+			CloudID: resp.CloudID,
+		},
+		Raw:      raw.Bytes(),
+		SyncedAt: time.Now(),
+	}
+
+	old, changed := c.applySyncConfig(seq, current)
+	if !changed {
+		return nil
 	}
+
+	c.conf.Logger.Info("sync config changed, agent-id: %s, cloud-id: %d", resp.AgentID, resp.CloudID)
+
+	if c.conf.OnConfigChanged == nil {
+		return nil
+	}
+
+	c.callbackMutex.Lock()
+	defer c.callbackMutex.Unlock()
+
+	// a newer change is already notified while waiting for the lock.
+	if seq <= c.notified {
+		return nil
+	}
+
+	c.notified = seq
+	c.conf.OnConfigChanged(old, current.clone())
+
+	return nil
+}
+
+// applySyncConfig applies the config of the seq-th keepalive response, returns the replaced one
+// and whether the config changed. the stale response is dropped as unchanged.
+func (c *client) applySyncConfig(seq uint64, current *SyncConfig) (*SyncConfig, bool) {
+	c.changeMutex.Lock()
+	defer c.changeMutex.Unlock()
+
+	if seq <= c.applied {
+		c.conf.Logger.Debug("drop stale keepalive(sync config) response: %s", string(current.Raw))
+		return nil, false
+	}
+
+	c.applied = seq
+
+	c.mutex.Lock()
+	old := c.syncConfig
+	c.syncConfig = current
 	c.mutex.Unlock()
 
-	c.conf.Logger.Debug("received keepalive(sync config) response: %v", resp)
+	c.client.Authorize()
+
+	c.conf.Logger.Debug("received keepalive(sync config) response: %s", string(current.Raw))
+
+	return old, old == nil || !bytes.Equal(old.Raw, current.Raw)
 }
 
-func (c *client) holdKeepalive() {
+func (c *client) holdKeepalive(stop <-chan struct{}) {
 	c.conf.Logger.Info("start sending keepalive(sync config) with interval %s", c.conf.KeepaliveInterval.String())
 
-	for ; ; time.Sleep(c.conf.KeepaliveInterval) {
+	ticker := time.NewTicker(c.conf.KeepaliveInterval)
+	defer ticker.Stop()
+
+	for {
+		c.sendKeepalive()
+
+		if c.conf.AgentStatusSource != nil {
+			c.gate.Update(c.conf.AgentStatusSource())
+		}
+
 		select {
-		case <-c.done:
+		case <-stop:
 			c.conf.Logger.Info("stop sending keepalive(sync config)")
 			return
 
-		default:
-			header := agent.NewDataUpHeader()
-			header.ProtoType = agent.ProtoTypeDataPluginSyncConfigReq
-			header.BodyLength = 0
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *client) sendKeepalive() {
+	header := agent.NewDataUpHeader()
+	header.ProtoType = agent.ProtoTypeDataPluginSyncConfigReq
+	header.BodyLength = 0
 
-			if err := c.client.SendMessage(context.Background(), header, nil); err != nil {
-				c.conf.Logger.Warn("send keepalive(sync config) request failed: %v", err)
-				continue
-			}
+	err := c.client.SendMessage(context.Background(), header, nil)
+	c.stats.Sent(int(header.HeaderLength()), err)
 
-			c.conf.Logger.Debug("send keepalive(sync config) request succeed")
-		}
+	if err != nil {
+		c.conf.Logger.Warn("send keepalive(sync config) request failed: %v", err)
+		return
 	}
+
+	c.stats.KeepaliveSent(time.Now())
+
+	c.conf.Logger.Debug("send keepalive(sync config) request succeed")
 }
@@ -0,0 +1,197 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentreport
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/agent"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// fakeAgent records the sent messages, the other methods of agent.Client are not implemented.
+type fakeAgent struct {
+	agent.Client
+
+	headers  []*agent.DataUpHeader
+	contents [][]byte
+	mutex    sync.Mutex
+}
+
+func (a *fakeAgent) Authorize() {}
+
+func (a *fakeAgent) Reconnects() uint64 { return 0 }
+
+func (a *fakeAgent) SendMessage(_ context.Context, header agent.IHeader, content []byte) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.headers = append(a.headers, header.(*agent.DataUpHeader))
+	a.contents = append(a.contents, content)
+
+	return nil
+}
+
+// newTestClient creates a client sending to the fake agent without connection.
+func newTestClient(t *testing.T, opts ...OptionFn) (*client, *fakeAgent) {
+	t.Helper()
+
+	conf := NewDefaultConfig()
+	conf.DomainSocketPath = "/tmp/agent.sock"
+	conf.Logger = types.NewEmptyLogger()
+
+	for _, opt := range opts {
+		opt(conf)
+	}
+
+	if err := conf.Validate(); err != nil {
+		t.Fatalf("invalid config: %v", err)
+	}
+
+	fake := new(fakeAgent)
+
+	return &client{
+		conf:    conf,
+		client:  fake,
+		limiter: newRateLimiter(conf),
+		gate:    internal.NewStatusGate(conf.AgentStatusPolicy, conf.AgentStatusQueueSize),
+		stats:   internal.NewStats(),
+	}, fake
+}
+
+func TestValidateTimestamp(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name        string
+		maxEventAge time.Duration
+		ts          time.Time
+		valid       bool
+	}{
+		{name: "now", ts: now, valid: true},
+		{name: "within clock skew", ts: now.Add(defaultMaxClockSkew), valid: true},
+		{name: "over clock skew", ts: now.Add(defaultMaxClockSkew + time.Second), valid: false},
+		{name: "old event without max age", ts: now.Add(-365 * 24 * time.Hour), valid: true},
+		{name: "within max age", maxEventAge: time.Hour, ts: now.Add(-time.Hour), valid: true},
+		{name: "over max age", maxEventAge: time.Hour, ts: now.Add(-time.Hour - time.Second), valid: false},
+		{name: "before epoch", ts: time.Unix(-1, 0), valid: false},
+		{name: "over uint32 seconds", ts: time.Unix(1<<32, 0), valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewDefaultConfig()
+			conf.MaxEventAge = tt.maxEventAge
+
+			c := &client{conf: conf}
+
+			err := c.validateTimestamp(tt.ts, now)
+			if tt.valid && err != nil {
+				t.Fatalf("validate returns %v, want valid", err)
+			}
+
+			if !tt.valid && !errors.Is(err, types.ErrInvalidTimestamp()) {
+				t.Fatalf("validate returns %v, want %v", err, types.ErrInvalidTimestamp())
+			}
+		})
+	}
+}
+
+func TestValidateAgentStatus(t *testing.T) {
+	source := func() types.AgentStatus { return types.AgentStatusRunning }
+
+	tests := []struct {
+		name      string
+		policy    types.AgentStatusPolicy
+		source    AgentStatusSource
+		queueSize int
+		valid     bool
+	}{
+		{name: "ignore without source", policy: types.AgentStatusPolicyIgnore, valid: true},
+		{name: "wait without source", policy: types.AgentStatusPolicyWait, valid: false},
+		{name: "reject with source", policy: types.AgentStatusPolicyReject, source: source, valid: true},
+		{name: "queue with source", policy: types.AgentStatusPolicyQueue, source: source, queueSize: 1, valid: true},
+		{name: "queue without size", policy: types.AgentStatusPolicyQueue, source: source, valid: false},
+		{name: "unknown policy", policy: types.AgentStatusPolicyQueue + 1, source: source, valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewDefaultConfig()
+			WithAgentStatusPolicy(tt.policy, tt.source)(conf)
+			WithAgentStatusQueueSize(tt.queueSize)(conf)
+
+			err := conf.validateAgentStatus()
+			if tt.valid && err != nil {
+				t.Fatalf("validate returns %v, want valid", err)
+			}
+
+			if !tt.valid && !errors.Is(err, types.ErrInvalidConfig()) {
+				t.Fatalf("validate returns %v, want %v", err, types.ErrInvalidConfig())
+			}
+		})
+	}
+}
+
+func TestHandleReceiveStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		protoType uint32
+		content   string
+		frames    uint64
+		errors    uint64
+	}{
+		{name: "sync config", protoType: agent.ProtoTypeDataPluginSyncConfigResp, content: `{"cloud_id":1}`, frames: 1},
+		{name: "invalid sync config", protoType: agent.ProtoTypeDataPluginSyncConfigResp, content: `{`, errors: 1},
+		{name: "unknown message type", protoType: 0x9999, content: `{}`, errors: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t)
+
+			header := agent.NewDataDownHeader()
+			header.ProtoType = tt.protoType
+			header.BodyLength = uint32(len(tt.content))
+
+			c.handleReceive(header, []byte(tt.content))
+
+			// the sync config is handled asynchronously, every frame is recorded once after handled.
+			var recv types.DirectionStats
+			var errs uint64
+
+			for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+				recv, errs = c.Stats().Recv, 0
+				for _, count := range recv.Errors {
+					errs += count
+				}
+
+				if recv.Frames+errs != 0 {
+					break
+				}
+			}
+
+			if recv.Frames != tt.frames || errs != tt.errors {
+				t.Fatalf("recorded %d frames and %d errors, want %d and %d", recv.Frames, errs, tt.frames, tt.errors)
+			}
+		})
+	}
+}
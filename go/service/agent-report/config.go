@@ -17,6 +17,7 @@ package agentreport
 
 import (
 	"errors"
+	"os"
 	"time"
 
 	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
@@ -25,12 +26,17 @@ import (
 // NewDefaultConfig creates a default configuration for agent-report service.
 func NewDefaultConfig() *Config {
 	return &Config{
-		DomainSocketPath:    "",
-		LocalSocketPort:     0,
-		ReconnectInterval:   defaultReconnectInterval,
-		KeepaliveInterval:   defaultKeepaliveInterval,
-		MaxMessageSizeBytes: defaultMaxMessageSizeBytes,
-		Logger:              types.NewDefaultLogger(defaultLoggerLevel),
+		DomainSocketPath:     "",
+		LocalSocketPort:      0,
+		ReconnectInterval:    defaultReconnectInterval,
+		KeepaliveInterval:    defaultKeepaliveInterval,
+		MaxMessageSizeBytes:  defaultMaxMessageSizeBytes,
+		MaxClockSkew:         defaultMaxClockSkew,
+		MaxEventAge:          0,
+		Hostname:             defaultHostname(),
+		AgentStatusPolicy:    types.AgentStatusPolicyIgnore,
+		AgentStatusQueueSize: defaultAgentStatusQueueSize,
+		Logger:               types.NewDefaultLogger(defaultLoggerLevel),
 	}
 }
 
@@ -39,8 +45,14 @@ const (
 	defaultKeepaliveInterval   = 3 * time.Second
 	defaultMaxMessageSizeBytes = 1024 * 1024 * 10
 	defaultLoggerLevel         = 1 // INFO
+	defaultMaxClockSkew        = 5 * time.Minute
+
+	defaultAgentStatusQueueSize = 1000
 )
 
+// AgentStatusSource returns the current agent status, e.g. agentmessage.Client.AgentStatus.
+type AgentStatusSource func() types.AgentStatus
+
 // Config defines the configuration for agent-report service.
 type Config struct {
 	// DomainSocketPath describes the agent report domain socket path on unix machine.
@@ -58,8 +70,48 @@ type Config struct {
 	// MaxMessageSizeBytes describes the max message size in bytes.
 	MaxMessageSizeBytes uint32
 
+	// MaxClockSkew describes how far the report time could be ahead of the local clock.
+	MaxClockSkew time.Duration
+
+	// MaxEventAge describes how far the report time could be behind the local clock, 0 means no limit.
+	MaxEventAge time.Duration
+
+	// PluginName describes the plugin's name who is using this SDK, it's filled in the reported events.
+	PluginName string
+
+	// PluginVersion describes the plugin's version who is using this SDK, it's filled in the reported events.
+	PluginVersion string
+
+	// Hostname describes the hostname filled in the reported events, default is the local hostname.
+	Hostname string
+
+	// Labels describes the common labels filled in the reported events.
+	Labels map[string]string
+
+	// OnConfigChanged describes the callback function when the config synced from agent changed, it's optional.
+	OnConfigChanged ConfigChangedCallback
+
+	// AgentStatusPolicy describes how to report while the agent is busy, upgrading, stopping or damage.
+	// the data report protocol carries no agent status, so the policy requires AgentStatusSource.
+	AgentStatusPolicy types.AgentStatusPolicy
+
+	// AgentStatusQueueSize describes the max number of queued reports with types.AgentStatusPolicyQueue.
+	AgentStatusQueueSize int
+
+	// AgentStatusSource describes where the agent status comes from, it's polled every keepalive interval.
+	AgentStatusSource AgentStatusSource
+
 	// Logger describes the logger for this service.
 	Logger types.Logger
+
+	// RateLimit describes the global rate limit of all reports, nil means no limit.
+	RateLimit *RateLimit
+
+	// DefaultDataIDRateLimit describes the rate limit of every data-id without its own limit, nil means no limit.
+	DefaultDataIDRateLimit *RateLimit
+
+	// DataIDRateLimits describes the rate limits of specified data-ids.
+	DataIDRateLimits map[uint32]RateLimit
 }
 
 // Validate validates the configuration.
@@ -76,9 +128,64 @@ func (c Config) Validate() error {
 		return errors.Join(types.ErrInvalidConfig(), errors.New("keepalive interval is 0"))
 	}
 
+	if c.MaxClockSkew < 0 || c.MaxEventAge < 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("max clock skew or max event age is negative"))
+	}
+
+	if err := c.validateAgentStatus(); err != nil {
+		return err
+	}
+
 	if c.Logger == nil {
 		return errors.Join(types.ErrInvalidConfig(), errors.New("logger is empty"))
 	}
 
+	return c.validateRateLimits()
+}
+
+func (c Config) validateAgentStatus() error {
+	if c.AgentStatusPolicy < types.AgentStatusPolicyIgnore || c.AgentStatusPolicy > types.AgentStatusPolicyQueue {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("unknown agent status policy"))
+	}
+
+	if c.AgentStatusPolicy == types.AgentStatusPolicyIgnore {
+		return nil
+	}
+
+	if c.AgentStatusSource == nil {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("agent status source is empty"))
+	}
+
+	if c.AgentStatusPolicy == types.AgentStatusPolicyQueue && c.AgentStatusQueueSize <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("agent status queue size is 0"))
+	}
+
+	return nil
+}
+
+func (c Config) validateRateLimits() error {
+	if c.RateLimit != nil {
+		if err := c.RateLimit.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.DefaultDataIDRateLimit != nil {
+		if err := c.DefaultDataIDRateLimit.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, limit := range c.DataIDRateLimits {
+		if err := limit.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+func defaultHostname() string {
+	hostname, _ := os.Hostname()
+	return hostname
+}
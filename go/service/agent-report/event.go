@@ -0,0 +1,131 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentreport
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// EventEnvelopeVersion is the version of the event envelope format.
+const EventEnvelopeVersion = 1
+
+// Event describes a structured event to report.
+type Event struct {
+	// Type describes the type of event, it's optional.
+	Type string
+
+	// SchemaVersion describes the version of the Data schema, it's optional.
+	SchemaVersion string
+
+	// Time describes the time when the event happened, zero means the report time.
+	Time time.Time
+
+	// Labels describes the labels of the event, it overrides the same labels in config.
+	Labels map[string]string
+
+	// Data describes the event data, it will be encoded in json.
+	// json.RawMessage could be used for data already encoded.
+	Data any
+}
+
+// EventEnvelope describes the standard envelope of the reported event.
+type EventEnvelope struct {
+	// Version is the version of envelope format, see EventEnvelopeVersion.
+	Version int `json:"version"`
+
+	// SchemaVersion is the version of the Data schema.
+	SchemaVersion string `json:"schema_version,omitempty"`
+
+	// Type is the type of event.
+	Type string `json:"type,omitempty"`
+
+	// EventTime is the time in unix milliseconds when the event happened.
+	EventTime int64 `json:"event_time"`
+
+	// ReportTime is the time in unix milliseconds when the event reported.
+	ReportTime int64 `json:"report_time"`
+
+	// AgentID and CloudID are from the agent sync config, empty if not synced yet.
+	AgentID string `json:"agent_id"`
+	CloudID int    `json:"cloud_id"`
+
+	Hostname      string            `json:"hostname,omitempty"`
+	PluginName    string            `json:"plugin_name,omitempty"`
+	PluginVersion string            `json:"plugin_version,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+
+	Data json.RawMessage `json:"data"`
+}
+
+// ReportEvent wraps the event in the standard envelope and sends it to server though agent.
+func (c *client) ReportEvent(ctx context.Context, dataID uint32, event Event) error {
+	now := time.Now()
+
+	eventTime := event.Time
+	if eventTime.IsZero() {
+		eventTime = now
+	} else if err := c.validateTimestamp(eventTime, now); err != nil {
+		c.stats.SentDataID(dataID, 0, err)
+		return err
+	}
+
+	content, err := c.encodeEvent(event, eventTime, now)
+	if err != nil {
+		return err
+	}
+
+	return c.reportData(ctx, dataID, eventTime, content)
+}
+
+func (c *client) encodeEvent(event Event, eventTime, reportTime time.Time) ([]byte, error) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	// the agent info is left empty if not synced yet.
+	var agentInfo types.AgentSimpleInfo
+	if syncConfig, err := c.GetSyncConfig(); err == nil {
+		agentInfo = syncConfig.AgentInfo
+	}
+
+	labels := c.conf.Labels
+	if len(event.Labels) != 0 {
+		labels = make(map[string]string, len(c.conf.Labels)+len(event.Labels))
+		maps.Copy(labels, c.conf.Labels)
+		maps.Copy(labels, event.Labels)
+	}
+
+	return json.Marshal(&EventEnvelope{
+		Version:       EventEnvelopeVersion,
+		SchemaVersion: event.SchemaVersion,
+		Type:          event.Type,
+		EventTime:     eventTime.UnixMilli(),
+		ReportTime:    reportTime.UnixMilli(),
+		AgentID:       agentInfo.AgentID,
+		CloudID:       agentInfo.CloudID,
+		Hostname:      c.conf.Hostname,
+		PluginName:    c.conf.PluginName,
+		PluginVersion: c.conf.PluginVersion,
+		Labels:        labels,
+		Data:          data,
+	})
+}
@@ -0,0 +1,126 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentreport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+func TestReportEvent(t *testing.T) {
+	c, fake := newTestClient(t,
+		WithPluginInfo("plugin", "1.0.0"),
+		WithHostname("host"),
+		WithLabels(map[string]string{"env": "prod", "zone": "a"}),
+	)
+
+	eventTime := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+
+	event := Event{
+		Type:          "login",
+		SchemaVersion: "v1",
+		Time:          eventTime,
+		Labels:        map[string]string{"zone": "b"},
+		Data:          json.RawMessage(`{"user":"admin"}`),
+	}
+
+	if err := c.ReportEvent(context.Background(), 100, event); err != nil {
+		t.Fatalf("report event failed: %v", err)
+	}
+
+	// the agent info is filled after synced.
+	c.handleKeepaliveResp(c.received.Add(1), nil, []byte(`{"bk_agent_id":"agent","cloud_id":1}`))
+
+	event.Time = time.Time{}
+	event.Labels = nil
+
+	before := time.Now().UnixMilli()
+	if err := c.ReportEvent(context.Background(), 100, event); err != nil {
+		t.Fatalf("report event failed: %v", err)
+	}
+
+	if len(fake.contents) != 2 {
+		t.Fatalf("%d events sent, want 2", len(fake.contents))
+	}
+
+	envelopes := make([]EventEnvelope, len(fake.contents))
+	for i, content := range fake.contents {
+		if err := json.Unmarshal(content, &envelopes[i]); err != nil {
+			t.Fatalf("decode event %d failed: %v", i, err)
+		}
+
+		if header := fake.headers[i]; header.DataID != 100 || header.BodyLength != uint32(len(content)) {
+			t.Fatalf("event %d sent in header %+v", i, header)
+		}
+	}
+
+	want := EventEnvelope{
+		Version:       EventEnvelopeVersion,
+		SchemaVersion: "v1",
+		Type:          "login",
+		EventTime:     eventTime.UnixMilli(),
+		ReportTime:    envelopes[0].ReportTime,
+		Hostname:      "host",
+		PluginName:    "plugin",
+		PluginVersion: "1.0.0",
+		Labels:        map[string]string{"env": "prod", "zone": "b"},
+		Data:          json.RawMessage(`{"user":"admin"}`),
+	}
+	if !reflect.DeepEqual(envelopes[0], want) {
+		t.Fatalf("event envelope %+v, want %+v", envelopes[0], want)
+	}
+
+	if fake.headers[0].UTCTime != uint32(eventTime.Unix()) {
+		t.Fatalf("event sent at %d, want event time %d", fake.headers[0].UTCTime, eventTime.Unix())
+	}
+
+	// the zero time means the report time, and the config labels are used alone.
+	second := envelopes[1]
+	if second.EventTime != second.ReportTime || second.EventTime < before {
+		t.Fatalf("event time %d and report time %d, want the same after %d", second.EventTime, second.ReportTime, before)
+	}
+
+	if second.AgentID != "agent" || second.CloudID != 1 {
+		t.Fatalf("event agent info is %s/%d, want agent/1", second.AgentID, second.CloudID)
+	}
+
+	if want := map[string]string{"env": "prod", "zone": "a"}; !reflect.DeepEqual(second.Labels, want) {
+		t.Fatalf("event labels %v, want %v", second.Labels, want)
+	}
+}
+
+func TestReportEventInvalid(t *testing.T) {
+	c, fake := newTestClient(t)
+
+	err := c.ReportEvent(context.Background(), 100, Event{Time: time.Now().Add(time.Hour)})
+	if !errors.Is(err, types.ErrInvalidTimestamp()) {
+		t.Fatalf("report event in the future returns %v, want %v", err, types.ErrInvalidTimestamp())
+	}
+
+	if err := c.ReportEvent(context.Background(), 100, Event{Data: make(chan int)}); err == nil {
+		t.Fatal("report event with data could not be encoded succeeded")
+	}
+
+	if len(fake.contents) != 0 {
+		t.Fatalf("%d invalid events sent, want 0", len(fake.contents))
+	}
+}
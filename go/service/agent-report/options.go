@@ -52,6 +52,90 @@ func WithKeepaliveInterval(interval time.Duration) OptionFn {
 	}
 }
 
+// WithMaxClockSkew sets how far the report time could be ahead of the local clock.
+func WithMaxClockSkew(skew time.Duration) OptionFn {
+	return func(c *Config) {
+		c.MaxClockSkew = skew
+	}
+}
+
+// WithMaxEventAge sets how far the report time could be behind the local clock, 0 means no limit.
+func WithMaxEventAge(age time.Duration) OptionFn {
+	return func(c *Config) {
+		c.MaxEventAge = age
+	}
+}
+
+// WithPluginInfo sets the plugin's name and version filled in the reported events.
+func WithPluginInfo(name, version string) OptionFn {
+	return func(c *Config) {
+		c.PluginName = name
+		c.PluginVersion = version
+	}
+}
+
+// WithHostname sets the hostname filled in the reported events.
+func WithHostname(hostname string) OptionFn {
+	return func(c *Config) {
+		c.Hostname = hostname
+	}
+}
+
+// WithLabels sets the common labels filled in the reported events.
+func WithLabels(labels map[string]string) OptionFn {
+	return func(c *Config) {
+		c.Labels = labels
+	}
+}
+
+// WithOnConfigChanged sets the callback function when the config synced from agent changed.
+func WithOnConfigChanged(callback ConfigChangedCallback) OptionFn {
+	return func(c *Config) {
+		c.OnConfigChanged = callback
+	}
+}
+
+// WithRateLimit sets the global rate limit of all reports.
+func WithRateLimit(limit RateLimit) OptionFn {
+	return func(c *Config) {
+		c.RateLimit = &limit
+	}
+}
+
+// WithDefaultDataIDRateLimit sets the rate limit of every data-id without its own limit.
+func WithDefaultDataIDRateLimit(limit RateLimit) OptionFn {
+	return func(c *Config) {
+		c.DefaultDataIDRateLimit = &limit
+	}
+}
+
+// WithDataIDRateLimit sets the rate limit of the data-id.
+func WithDataIDRateLimit(dataID uint32, limit RateLimit) OptionFn {
+	return func(c *Config) {
+		if c.DataIDRateLimits == nil {
+			c.DataIDRateLimits = make(map[uint32]RateLimit)
+		}
+
+		c.DataIDRateLimits[dataID] = limit
+	}
+}
+
+// WithAgentStatusPolicy sets how to report while the agent is busy, upgrading, stopping or damage,
+// the agent status is polled from source, e.g. agentmessage.Client.AgentStatus.
+func WithAgentStatusPolicy(policy types.AgentStatusPolicy, source AgentStatusSource) OptionFn {
+	return func(c *Config) {
+		c.AgentStatusPolicy = policy
+		c.AgentStatusSource = source
+	}
+}
+
+// WithAgentStatusQueueSize sets the max number of queued reports with types.AgentStatusPolicyQueue.
+func WithAgentStatusQueueSize(size int) OptionFn {
+	return func(c *Config) {
+		c.AgentStatusQueueSize = size
+	}
+}
+
 // WithLogger sets the logger.
 func WithLogger(logger types.Logger) OptionFn {
 	return func(c *Config) {
@@ -0,0 +1,360 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/ratelimit"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// OverflowPolicy describes how to handle the records over rate limit.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the report until it's allowed or the context is done.
+	OverflowBlock OverflowPolicy = 0
+
+	// OverflowDrop drops the record and returns types.ErrRateLimited.
+	OverflowDrop OverflowPolicy = 1
+
+	// OverflowSample reports one of every SampleEvery overflow records, and drops the others.
+	OverflowSample OverflowPolicy = 2
+)
+
+// RateLimit describes the token bucket rate limit of reports.
+type RateLimit struct {
+	// RecordsPerSecond limits the number of reports per second, 0 means no limit.
+	RecordsPerSecond float64
+
+	// BytesPerSecond limits the size of reports per second, 0 means no limit.
+	BytesPerSecond float64
+
+	// BurstRecords, BurstBytes describes the bucket size, default to one second of rate.
+	BurstRecords float64
+	BurstBytes   float64
+
+	// Policy describes how to handle the records over limit.
+	Policy OverflowPolicy
+
+	// SampleEvery describes the sample interval of OverflowSample.
+	SampleEvery uint64
+}
+
+// Validate validates the rate limit.
+func (l RateLimit) Validate() error {
+	if l.RecordsPerSecond < 0 || l.BytesPerSecond < 0 || l.BurstRecords < 0 || l.BurstBytes < 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("rate limit is negative"))
+	}
+
+	switch l.Policy {
+	case OverflowBlock, OverflowDrop:
+	case OverflowSample:
+		if l.SampleEvery == 0 {
+			return errors.Join(types.ErrInvalidConfig(), errors.New("rate limit sample interval is 0"))
+		}
+
+	default:
+		return errors.Join(types.ErrInvalidConfig(), fmt.Errorf("unknown overflow policy %d", l.Policy))
+	}
+
+	return nil
+}
+
+// RateLimitCounters describes the counters of records over rate limit.
+type RateLimitCounters struct {
+	// DroppedRecords, DroppedBytes counts the dropped records.
+	DroppedRecords uint64
+	DroppedBytes   uint64
+
+	// SampledRecords counts the overflow records reported by sampling.
+	SampledRecords uint64
+
+	// BlockedRecords counts the records which waited for the limit.
+	BlockedRecords uint64
+}
+
+// RateLimitStats describes the statistics of rate limit.
+type RateLimitStats struct {
+	// Global counts the records over the global limit.
+	Global RateLimitCounters
+
+	// DataIDs counts the records over any limit by data-id.
+	DataIDs map[uint32]RateLimitCounters
+}
+
+// rateLimiter limits the reports with global and per data-id limits.
+type rateLimiter struct {
+	global       *limiter
+	dataIDs      map[uint32]*limiter
+	dataIDLimits map[uint32]RateLimit
+	defaultLimit *RateLimit
+
+	// counters by data-id.
+	counters map[uint32]*RateLimitCounters
+	mutex    sync.Mutex
+}
+
+// limiter is a single rate limit.
+type limiter struct {
+	limit      RateLimit
+	records    *ratelimit.Bucket
+	bytes      *ratelimit.Bucket
+	overflowed uint64
+	counters   RateLimitCounters
+}
+
+func newRateLimiter(conf *Config) *rateLimiter {
+	r := &rateLimiter{
+		dataIDs:      make(map[uint32]*limiter),
+		dataIDLimits: conf.DataIDRateLimits,
+		defaultLimit: conf.DefaultDataIDRateLimit,
+		counters:     make(map[uint32]*RateLimitCounters),
+	}
+
+	if conf.RateLimit != nil {
+		r.global = newLimiter(*conf.RateLimit, time.Now())
+	}
+
+	return r
+}
+
+func newLimiter(limit RateLimit, now time.Time) *limiter {
+	l := &limiter{limit: limit}
+
+	if limit.RecordsPerSecond > 0 {
+		burst := limit.BurstRecords
+		if burst == 0 {
+			burst = limit.RecordsPerSecond
+		}
+
+		l.records = ratelimit.NewBucket(limit.RecordsPerSecond, burst, now)
+	}
+
+	if limit.BytesPerSecond > 0 {
+		burst := limit.BurstBytes
+		if burst == 0 {
+			burst = limit.BytesPerSecond
+		}
+
+		l.bytes = ratelimit.NewBucket(limit.BytesPerSecond, burst, now)
+	}
+
+	return l
+}
+
+// admit waits or decides whether the record of data-id could be reported.
+// the tokens are reserved from the data-id and global limiters together before waiting,
+// and refunded if the record is dropped by any of them or the waiting is canceled.
+func (r *rateLimiter) admit(ctx context.Context, dataID uint32, size int) error {
+	r.mutex.Lock()
+
+	now := time.Now()
+
+	var (
+		wait     time.Duration
+		reserved []*limiter
+	)
+
+	for _, l := range []*limiter{r.dataIDLimiter(dataID), r.global} {
+		if l == nil {
+			continue
+		}
+
+		lWait, took, err := r.admitBy(l, dataID, size, now)
+		if err != nil {
+			refund(reserved, size, now)
+			r.mutex.Unlock()
+
+			return err
+		}
+
+		if took {
+			reserved = append(reserved, l)
+		}
+
+		wait = max(wait, lWait)
+	}
+
+	r.mutex.Unlock()
+
+	if err := sleep(ctx, wait); err != nil {
+		r.mutex.Lock()
+		refund(reserved, size, time.Now())
+		r.mutex.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// admitBy reserves or takes the tokens of record from the limiter, it must be called with mutex held.
+// returns how long to wait and whether the tokens are taken, the sampled record is admitted without tokens.
+func (r *rateLimiter) admitBy(l *limiter, dataID uint32, size int, now time.Time) (time.Duration, bool, error) {
+	counters := r.countersOf(dataID)
+
+	if l.limit.Policy == OverflowBlock {
+		wait := l.reserve(size, now)
+		if wait > 0 {
+			l.counters.BlockedRecords++
+			counters.BlockedRecords++
+		}
+
+		return wait, true, nil
+	}
+
+	if l.take(size, now) {
+		return 0, true, nil
+	}
+
+	l.overflowed++
+	if l.limit.Policy == OverflowSample && l.overflowed%l.limit.SampleEvery == 1%l.limit.SampleEvery {
+		l.counters.SampledRecords++
+		counters.SampledRecords++
+
+		return 0, false, nil
+	}
+
+	l.counters.DroppedRecords++
+	l.counters.DroppedBytes += uint64(size)
+	counters.DroppedRecords++
+	counters.DroppedBytes += uint64(size)
+
+	return 0, false, errors.Join(types.ErrRateLimited(), fmt.Errorf("record of data-id %d dropped", dataID))
+}
+
+// refund returns the tokens of record to the limiters, it must be called with mutex held.
+func refund(limiters []*limiter, size int, now time.Time) {
+	for _, l := range limiters {
+		if l.records != nil {
+			l.records.Refund(1, now)
+		}
+
+		if l.bytes != nil {
+			l.bytes.Refund(float64(size), now)
+		}
+	}
+}
+
+// dataIDLimiter returns the limiter of data-id, it must be called with mutex held.
+func (r *rateLimiter) dataIDLimiter(dataID uint32) *limiter {
+	if l, ok := r.dataIDs[dataID]; ok {
+		return l
+	}
+
+	limit, ok := r.dataIDLimits[dataID]
+	if !ok {
+		if r.defaultLimit == nil {
+			return nil
+		}
+
+		limit = *r.defaultLimit
+	}
+
+	l := newLimiter(limit, time.Now())
+	r.dataIDs[dataID] = l
+
+	return l
+}
+
+// countersOf returns the counters of data-id, it must be called with mutex held.
+func (r *rateLimiter) countersOf(dataID uint32) *RateLimitCounters {
+	counters, ok := r.counters[dataID]
+	if !ok {
+		counters = new(RateLimitCounters)
+		r.counters[dataID] = counters
+	}
+
+	return counters
+}
+
+func (r *rateLimiter) stats() RateLimitStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stats := RateLimitStats{
+		DataIDs: make(map[uint32]RateLimitCounters, len(r.counters)),
+	}
+
+	if r.global != nil {
+		stats.Global = r.global.counters
+	}
+
+	for dataID, counters := range r.counters {
+		stats.DataIDs[dataID] = *counters
+	}
+
+	return stats
+}
+
+// take takes the tokens of record only if both buckets have enough.
+func (l *limiter) take(size int, now time.Time) bool {
+	if l.records != nil && !l.records.Available(1, now) {
+		return false
+	}
+
+	if l.bytes != nil && !l.bytes.Available(float64(size), now) {
+		return false
+	}
+
+	if l.records != nil {
+		l.records.Take(1, now)
+	}
+
+	if l.bytes != nil {
+		l.bytes.Take(float64(size), now)
+	}
+
+	return true
+}
+
+// reserve takes the tokens of record into debt, returns how long to wait.
+func (l *limiter) reserve(size int, now time.Time) time.Duration {
+	var wait time.Duration
+
+	if l.records != nil {
+		wait = max(wait, l.records.Reserve(1, now))
+	}
+
+	if l.bytes != nil {
+		wait = max(wait, l.bytes.Reserve(float64(size), now))
+	}
+
+	return wait
+}
+
+func sleep(ctx context.Context, wait time.Duration) error {
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return errors.Join(types.ErrContextDone(), ctx.Err())
+
+	case <-timer.C:
+		return nil
+	}
+}
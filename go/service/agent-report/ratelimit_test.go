@@ -0,0 +1,84 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentreport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+func TestRateLimiterRefundOnGlobalDrop(t *testing.T) {
+	r := newRateLimiter(&Config{
+		RateLimit: &RateLimit{RecordsPerSecond: 1, Policy: OverflowDrop},
+		DataIDRateLimits: map[uint32]RateLimit{
+			1: {RecordsPerSecond: 10, Policy: OverflowDrop},
+		},
+	})
+
+	if err := r.admit(context.Background(), 1, 1); err != nil {
+		t.Fatalf("first record is not admitted: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if err := r.admit(context.Background(), 1, 1); !errors.Is(err, types.ErrRateLimited()) {
+			t.Fatalf("record over global limit returns %v, want %v", err, types.ErrRateLimited())
+		}
+	}
+
+	// only the admitted record spends the tokens of data-id.
+	if !r.dataIDs[1].records.Available(9, time.Now()) {
+		t.Fatal("tokens of data-id are spent by the records dropped by global limit")
+	}
+
+	stats := r.stats()
+	if stats.Global.DroppedRecords != 5 || stats.DataIDs[1].DroppedRecords != 5 {
+		t.Fatalf("dropped records are %d in global and %d in data-id, want 5",
+			stats.Global.DroppedRecords, stats.DataIDs[1].DroppedRecords)
+	}
+}
+
+func TestRateLimiterRefundOnCancel(t *testing.T) {
+	r := newRateLimiter(&Config{
+		RateLimit: &RateLimit{RecordsPerSecond: 10, BurstRecords: 1, Policy: OverflowBlock},
+	})
+
+	if err := r.admit(context.Background(), 1, 1); err != nil {
+		t.Fatalf("first record is not admitted: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		if err := r.admit(ctx, 1, 1); !errors.Is(err, types.ErrContextDone()) {
+			t.Fatalf("blocked record returns %v, want %v", err, types.ErrContextDone())
+		}
+	}
+
+	// the canceled records return their reservations, so the next one waits for a single token only.
+	start := time.Now()
+	if err := r.admit(context.Background(), 1, 1); err != nil {
+		t.Fatalf("record after canceled ones is not admitted: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Fatalf("record after canceled ones waits %s, the canceled reservations are not refunded", elapsed)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentreport
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// SyncConfig describes the config synced from agent in the keepalive(sync config) response.
+type SyncConfig struct {
+	// AgentInfo is the known agent info in the config.
+	AgentInfo types.AgentSimpleInfo
+
+	// Raw is the full raw payload of the config, including the fields unknown to SDK.
+	Raw json.RawMessage
+
+	// SyncedAt is the time when the config is received.
+	SyncedAt time.Time
+}
+
+// clone returns a copy of the config, so that the callers could not modify the one held by client.
+func (s *SyncConfig) clone() *SyncConfig {
+	clone := *s
+	clone.Raw = slices.Clone(s.Raw)
+
+	return &clone
+}
+
+// Decode decodes the raw payload into v, to access the fields unknown to SDK.
+func (s *SyncConfig) Decode(v any) error {
+	return json.Unmarshal(s.Raw, v)
+}
+
+// Field returns the raw value of the top-level field in the payload.
+func (s *SyncConfig) Field(name string) (json.RawMessage, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(s.Raw, &fields); err != nil {
+		return nil, false
+	}
+
+	value, ok := fields[name]
+
+	return value, ok
+}
+
+// ConfigChangedCallback defines the callback function when the synced config changed.
+// old is nil for the first synced config. the callbacks are called one by one in receiving order and out of
+// the client locks, so it's fine to call the client in it, including Terminate. a change superseded by a newer
+// one before notified is skipped.
+type ConfigChangedCallback func(old, current *SyncConfig)
@@ -0,0 +1,122 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agentreport
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+func TestSyncConfig(t *testing.T) {
+	s := &SyncConfig{Raw: []byte(`{"bk_agent_id":"agent","cloud_id":1,"extra":{"level":"debug"}}`)}
+
+	extra, ok := s.Field("extra")
+	if !ok || string(extra) != `{"level":"debug"}` {
+		t.Fatalf("field extra is %s, %v", extra, ok)
+	}
+
+	if _, ok := s.Field("missing"); ok {
+		t.Fatal("missing field is found")
+	}
+
+	var decoded struct {
+		Extra struct {
+			Level string `json:"level"`
+		} `json:"extra"`
+	}
+	if err := s.Decode(&decoded); err != nil || decoded.Extra.Level != "debug" {
+		t.Fatalf("decode returns %+v, %v", decoded, err)
+	}
+
+	// the clone shares nothing with the config.
+	clone := s.clone()
+	clone.Raw[0] = '['
+
+	if s.Raw[0] != '{' {
+		t.Fatal("modifying the clone changes the config")
+	}
+}
+
+func TestHandleKeepaliveResp(t *testing.T) {
+	type change struct {
+		old, current string
+	}
+
+	var changes []change
+
+	c, _ := newTestClient(t, WithOnConfigChanged(func(old, current *SyncConfig) {
+		oldRaw := ""
+		if old != nil {
+			oldRaw = string(old.Raw)
+		}
+
+		changes = append(changes, change{old: oldRaw, current: string(current.Raw)})
+	}))
+
+	if _, err := c.GetAgentInfo(); !errors.Is(err, types.ErrNotSynced()) {
+		t.Fatalf("get agent info before synced returns %v, want %v", err, types.ErrNotSynced())
+	}
+
+	first := `{"bk_agent_id":"agent","cloud_id":1}`
+	second := `{"bk_agent_id":"agent","cloud_id":2}`
+
+	c.handleKeepaliveResp(c.received.Add(1), nil, []byte(first))
+	// the same config in another format is unchanged.
+	c.handleKeepaliveResp(c.received.Add(1), nil, []byte(`{"bk_agent_id": "agent", "cloud_id": 1}`))
+
+	// the stale response received before the newest one applied is dropped.
+	stale := c.received.Add(1)
+	c.handleKeepaliveResp(c.received.Add(1), nil, []byte(second))
+	c.handleKeepaliveResp(stale, nil, []byte(first))
+
+	want := []change{{old: "", current: first}, {old: first, current: second}}
+	if len(changes) != len(want) || changes[0] != want[0] || changes[1] != want[1] {
+		t.Fatalf("config changes %v, want %v", changes, want)
+	}
+
+	info, err := c.GetAgentInfo()
+	if err != nil || info.AgentID != "agent" || info.CloudID != 2 {
+		t.Fatalf("get agent info returns %+v, %v", info, err)
+	}
+}
+
+func TestOnConfigChangedTerminate(t *testing.T) {
+	var c *client
+
+	c, _ = newTestClient(t, WithOnConfigChanged(func(_, _ *SyncConfig) {
+		// Terminate resets the synced config in the callback.
+		c.resetSyncConfig()
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.handleKeepaliveResp(c.received.Add(1), nil, []byte(`{"bk_agent_id":"agent","bk_cloud_id":1}`))
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("config changed callback which terminates the client is deadlocked")
+	}
+
+	if _, err := c.GetSyncConfig(); err == nil {
+		t.Fatal("sync config is kept after reset in callback")
+	}
+}
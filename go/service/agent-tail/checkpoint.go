@@ -0,0 +1,106 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agenttail
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// checkpointVersion is the version of the checkpoint file format.
+const checkpointVersion = 1
+
+// checkpoint describes the checkpoint file.
+type checkpoint struct {
+	Version int                `json:"version"`
+	Files   []checkpointRecord `json:"files"`
+}
+
+// checkpointRecord describes the read offset of a file.
+type checkpointRecord struct {
+	// Path is the last known path of the file.
+	Path string `json:"path"`
+
+	// ID is the identity of the file, see fileID.
+	ID string `json:"id"`
+
+	// Offset is the offset of the first byte not reported yet.
+	Offset int64 `json:"offset"`
+}
+
+// loadCheckpoint loads the offsets by file identity, a missing checkpoint file means no offsets.
+func loadCheckpoint(path string) (map[string]checkpointRecord, error) {
+	offsets := make(map[string]checkpointRecord)
+	if path == "" {
+		return offsets, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return offsets, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+
+	for _, record := range cp.Files {
+		offsets[record.ID] = record
+	}
+
+	return offsets, nil
+}
+
+// saveCheckpoint saves the offsets into a temporary file and renames it, so the checkpoint is never partial.
+func saveCheckpoint(path string, records []checkpointRecord) error {
+	data, err := json.Marshal(&checkpoint{Version: checkpointVersion, Files: records})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
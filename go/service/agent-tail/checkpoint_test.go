@@ -0,0 +1,113 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agenttail
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckpointSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	records := []checkpointRecord{
+		{Path: "/var/log/a.log", ID: "1-2", Offset: 10},
+		{Path: "/var/log/b.log", ID: "1-3", Offset: 0},
+	}
+
+	if err := saveCheckpoint(path, records); err != nil {
+		t.Fatalf("save checkpoint failed: %v", err)
+	}
+
+	// saving again replaces the checkpoint.
+	records[0].Offset = 20
+	if err := saveCheckpoint(path, records); err != nil {
+		t.Fatalf("save checkpoint failed: %v", err)
+	}
+
+	offsets, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("load checkpoint failed: %v", err)
+	}
+
+	want := map[string]checkpointRecord{"1-2": records[0], "1-3": records[1]}
+	if !reflect.DeepEqual(offsets, want) {
+		t.Fatalf("loaded offsets %v, want %v", offsets, want)
+	}
+
+	// no temporary file is left.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("read dir failed: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("%d files in checkpoint dir, want 1", len(entries))
+	}
+}
+
+func TestCheckpointLoadMissing(t *testing.T) {
+	for _, path := range []string{"", filepath.Join(t.TempDir(), "missing.json")} {
+		offsets, err := loadCheckpoint(path)
+		if err != nil || len(offsets) != 0 {
+			t.Fatalf("load checkpoint %q returns %v, %v, want no offsets", path, offsets, err)
+		}
+	}
+}
+
+func TestCheckpointLoadCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatalf("write checkpoint failed: %v", err)
+	}
+
+	if _, err := loadCheckpoint(path); err == nil {
+		t.Fatal("corrupt checkpoint is loaded")
+	}
+}
+
+func TestTailerSaveCheckpoint(t *testing.T) {
+	conf := NewDefaultConfig()
+	conf.CheckpointPath = filepath.Join(t.TempDir(), "checkpoint.json")
+
+	missing := checkpointRecord{Path: "/var/log/missing.log", ID: "1-3", Offset: 30}
+
+	tl := &tailer{
+		conf:    conf,
+		offsets: map[string]checkpointRecord{missing.ID: missing},
+		readers: map[string]*reader{"1-2": {path: "/var/log/a.log", committed: 10}},
+	}
+
+	if err := tl.saveCheckpoint(); err != nil {
+		t.Fatalf("save checkpoint failed: %v", err)
+	}
+
+	offsets, err := loadCheckpoint(conf.CheckpointPath)
+	if err != nil {
+		t.Fatalf("load checkpoint failed: %v", err)
+	}
+
+	// the offset of the file not found since loaded is carried over.
+	want := map[string]checkpointRecord{
+		"1-2":      {Path: "/var/log/a.log", ID: "1-2", Offset: 10},
+		missing.ID: missing,
+	}
+	if !reflect.DeepEqual(offsets, want) {
+		t.Fatalf("loaded offsets %v, want %v", offsets, want)
+	}
+}
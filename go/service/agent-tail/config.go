@@ -0,0 +1,131 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agenttail
+
+import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// NewDefaultConfig creates a default configuration for file tailer.
+func NewDefaultConfig() *Config {
+	return &Config{
+		Paths:              nil,
+		DataID:             0,
+		ReadFromHead:       false,
+		Multiline:          nil,
+		MultilineTimeout:   defaultMultilineTimeout,
+		MaxRecordBytes:     defaultMaxRecordBytes,
+		PollInterval:       defaultPollInterval,
+		RetryInterval:      defaultRetryInterval,
+		CheckpointPath:     "",
+		CheckpointInterval: defaultCheckpointInterval,
+		Logger:             types.NewDefaultLogger(defaultLoggerLevel),
+	}
+}
+
+const (
+	defaultMultilineTimeout   = 1 * time.Second
+	defaultMaxRecordBytes     = 1024 * 1024
+	defaultPollInterval       = 1 * time.Second
+	defaultRetryInterval      = 1 * time.Second
+	defaultCheckpointInterval = 5 * time.Second
+	defaultLoggerLevel        = 1 // INFO
+
+	// readBufferSize is the size of every read from file.
+	readBufferSize = 64 * 1024
+)
+
+// Config defines the configuration for file tailer.
+type Config struct {
+	// Paths describes the glob patterns of the files to tail, see filepath.Match for the syntax.
+	Paths []string
+
+	// DataID describes the data-id which the records are reported to.
+	DataID uint32
+
+	// ReadFromHead describes whether to read the files existing at start without checkpoint from the head,
+	// otherwise from the end. the files created after start are always read from the head.
+	ReadFromHead bool
+
+	// Multiline describes the pattern of the first line of a multiline record,
+	// the following lines not matching it are joined into the record, nil means every line is a record.
+	Multiline *regexp.Regexp
+
+	// MultilineTimeout describes how long to wait for the following lines before reporting a multiline record.
+	MultilineTimeout time.Duration
+
+	// MaxRecordBytes describes the max size in bytes of a record, the longer line or record is split.
+	MaxRecordBytes int
+
+	// PollInterval describes the interval of finding files and reading new contents.
+	PollInterval time.Duration
+
+	// RetryInterval describes the interval of retrying a failed report.
+	RetryInterval time.Duration
+
+	// CheckpointPath describes the file to persist the read offsets, empty means no persistence.
+	CheckpointPath string
+
+	// CheckpointInterval describes the interval of saving checkpoint.
+	CheckpointInterval time.Duration
+
+	// Logger describes the logger for file tailer.
+	Logger types.Logger
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	if len(c.Paths) == 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("paths is empty"))
+	}
+
+	for _, path := range c.Paths {
+		if _, err := filepath.Match(path, ""); err != nil {
+			return errors.Join(types.ErrInvalidConfig(), err)
+		}
+	}
+
+	if c.DataID == 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("data id is 0"))
+	}
+
+	if c.Multiline != nil && c.MultilineTimeout <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("multiline timeout is 0"))
+	}
+
+	if c.MaxRecordBytes <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("max record bytes is 0"))
+	}
+
+	if c.PollInterval <= 0 || c.RetryInterval <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("poll interval or retry interval is 0"))
+	}
+
+	if c.CheckpointPath != "" && c.CheckpointInterval <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("checkpoint interval is 0"))
+	}
+
+	if c.Logger == nil {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("logger is empty"))
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+//go:build windows
+
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agenttail
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fileID returns the identity of file which keeps unchanged after renaming, in creation time.
+func fileID(info os.FileInfo) string {
+	attr, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%d", attr.CreationTime.Nanoseconds())
+}
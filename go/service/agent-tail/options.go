@@ -0,0 +1,91 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agenttail
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// OptionFn defines the function type for setting options.
+type OptionFn func(*Config)
+
+// WithPaths sets the glob patterns of the files to tail.
+func WithPaths(paths ...string) OptionFn {
+	return func(c *Config) {
+		c.Paths = paths
+	}
+}
+
+// WithDataID sets the data-id which the records are reported to.
+func WithDataID(dataID uint32) OptionFn {
+	return func(c *Config) {
+		c.DataID = dataID
+	}
+}
+
+// WithReadFromHead sets whether to read the files existing at start without checkpoint from the head.
+func WithReadFromHead(fromHead bool) OptionFn {
+	return func(c *Config) {
+		c.ReadFromHead = fromHead
+	}
+}
+
+// WithMultiline sets the pattern of the first line of a multiline record and the waiting timeout.
+func WithMultiline(start *regexp.Regexp, timeout time.Duration) OptionFn {
+	return func(c *Config) {
+		c.Multiline = start
+		c.MultilineTimeout = timeout
+	}
+}
+
+// WithMaxRecordBytes sets the max size in bytes of a record.
+func WithMaxRecordBytes(size int) OptionFn {
+	return func(c *Config) {
+		c.MaxRecordBytes = size
+	}
+}
+
+// WithPollInterval sets the interval of finding files and reading new contents.
+func WithPollInterval(interval time.Duration) OptionFn {
+	return func(c *Config) {
+		c.PollInterval = interval
+	}
+}
+
+// WithRetryInterval sets the interval of retrying a failed report.
+func WithRetryInterval(interval time.Duration) OptionFn {
+	return func(c *Config) {
+		c.RetryInterval = interval
+	}
+}
+
+// WithCheckpoint sets the file to persist the read offsets and the saving interval.
+func WithCheckpoint(path string, interval time.Duration) OptionFn {
+	return func(c *Config) {
+		c.CheckpointPath = path
+		c.CheckpointInterval = interval
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(logger types.Logger) OptionFn {
+	return func(c *Config) {
+		c.Logger = logger
+	}
+}
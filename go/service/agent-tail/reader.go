@@ -0,0 +1,243 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agenttail
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// reportFunc reports a record, it only fails when the tailer is stopping.
+type reportFunc func(content []byte) error
+
+// reader reads the records from a file, tracks the offset of the first byte not reported yet.
+type reader struct {
+	conf *Config
+
+	path string
+	id   string
+	file *os.File
+
+	// offset is the offset of the next byte to read, buf holds the last line without '\n' before it.
+	offset int64
+	buf    []byte
+
+	// record is the pending multiline record, recordAt is the time of its last line.
+	record    []byte
+	recordAt  time.Time
+	hasRecord bool
+
+	// committed is the offset of the first byte not reported yet, it's saved into checkpoint.
+	committed int64
+}
+
+// openReader opens the file and seeks to the offset, offset over the file size means it's truncated.
+func openReader(conf *Config, path string, info os.FileInfo, offset int64) (*reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	opened, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	// the file is rotated between finding and opening, leave it to the next poll.
+	if !os.SameFile(info, opened) {
+		_ = file.Close()
+		return nil, errors.New("file changed during opening")
+	}
+
+	if offset > opened.Size() {
+		offset = 0
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &reader{
+		conf:      conf,
+		path:      path,
+		id:        fileID(opened),
+		file:      file,
+		offset:    offset,
+		committed: offset,
+	}, nil
+}
+
+// checkTruncate reads from the head again if the file is truncated.
+func (r *reader) checkTruncate() (bool, error) {
+	info, err := r.file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	if info.Size() >= r.offset {
+		return false, nil
+	}
+
+	if _, err := r.file.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	r.offset, r.committed = 0, 0
+	r.buf, r.record, r.hasRecord = nil, nil, false
+
+	return true, nil
+}
+
+// read reads and reports the new records, returns whether any byte is read.
+func (r *reader) read(now time.Time, report reportFunc) (bool, error) {
+	chunk := make([]byte, readBufferSize)
+	read := false
+
+	for {
+		n, err := r.file.Read(chunk)
+		if n > 0 {
+			read = true
+			start := r.offset - int64(len(r.buf))
+
+			r.buf = append(r.buf, chunk[:n]...)
+			r.offset += int64(n)
+
+			if err := r.split(start, now, report); err != nil {
+				return read, err
+			}
+		}
+
+		if errors.Is(err, io.EOF) || (n == 0 && err == nil) {
+			break
+		}
+
+		if err != nil {
+			return read, err
+		}
+	}
+
+	if r.hasRecord && len(r.buf) == 0 && now.Sub(r.recordAt) >= r.conf.MultilineTimeout {
+		return read, r.emitRecord(r.offset, report)
+	}
+
+	return read, nil
+}
+
+// finish reports the last line without '\n' and the pending record, it's called before closing a rotated file.
+func (r *reader) finish(now time.Time, report reportFunc) error {
+	if len(r.buf) > 0 {
+		start := r.offset - int64(len(r.buf))
+		if err := r.handleLine(r.buf, start, r.offset, now, report); err != nil {
+			return err
+		}
+
+		r.buf = nil
+	}
+
+	if r.hasRecord {
+		return r.emitRecord(r.offset, report)
+	}
+
+	return nil
+}
+
+func (r *reader) close() {
+	_ = r.file.Close()
+}
+
+// split handles the complete lines in buf which starts at offset start, the overlong line is split.
+func (r *reader) split(start int64, now time.Time, report reportFunc) error {
+	for {
+		pos := bytes.IndexByte(r.buf, '\n')
+
+		var line []byte
+		var end int64
+
+		switch {
+		case pos >= 0 && pos <= r.conf.MaxRecordBytes:
+			line, end = bytes.TrimSuffix(r.buf[:pos], []byte{'\r'}), start+int64(pos)+1
+			r.buf = r.buf[pos+1:]
+
+		case pos >= 0 || len(r.buf) >= r.conf.MaxRecordBytes:
+			line, end = r.buf[:r.conf.MaxRecordBytes], start+int64(r.conf.MaxRecordBytes)
+			r.buf = r.buf[r.conf.MaxRecordBytes:]
+
+		default:
+			if len(r.buf) == 0 {
+				r.buf = nil
+			}
+
+			return nil
+		}
+
+		if err := r.handleLine(line, start, end, now, report); err != nil {
+			return err
+		}
+
+		start = end
+	}
+}
+
+// handleLine reports the line or joins it into the pending multiline record.
+func (r *reader) handleLine(line []byte, start, end int64, now time.Time, report reportFunc) error {
+	if r.conf.Multiline == nil {
+		if len(line) > 0 {
+			if err := report(line); err != nil {
+				return err
+			}
+		}
+
+		r.committed = end
+
+		return nil
+	}
+
+	if r.hasRecord && (r.conf.Multiline.Match(line) || len(r.record)+1+len(line) > r.conf.MaxRecordBytes) {
+		if err := r.emitRecord(start, report); err != nil {
+			return err
+		}
+	}
+
+	if r.hasRecord {
+		r.record = append(r.record, '\n')
+		r.record = append(r.record, line...)
+	} else {
+		r.record = append([]byte(nil), line...)
+		r.hasRecord = true
+		r.committed = start
+	}
+
+	r.recordAt = now
+
+	return nil
+}
+
+// emitRecord reports the pending record, end is the offset after it.
+func (r *reader) emitRecord(end int64, report reportFunc) error {
+	if err := report(r.record); err != nil {
+		return err
+	}
+
+	r.record, r.hasRecord = nil, false
+	r.committed = end
+
+	return nil
+}
@@ -0,0 +1,247 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package agenttail
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// openTestReader writes the content into a temporary file and opens a reader on it from offset.
+func openTestReader(t *testing.T, conf *Config, content string, offset int64) *reader {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat file failed: %v", err)
+	}
+
+	r, err := openReader(conf, path, info, offset)
+	if err != nil {
+		t.Fatalf("open reader failed: %v", err)
+	}
+
+	t.Cleanup(r.close)
+
+	return r
+}
+
+// collect returns a reportFunc which appends the records.
+func collect(records *[]string) reportFunc {
+	return func(content []byte) error {
+		*records = append(*records, string(content))
+		return nil
+	}
+}
+
+func TestReaderRead(t *testing.T) {
+	multiline := regexp.MustCompile(`^\d`)
+
+	tests := []struct {
+		name      string
+		content   string
+		multiline *regexp.Regexp
+		maxBytes  int
+		offset    int64
+		records   []string
+		committed int64
+	}{
+		{
+			name:      "complete lines",
+			content:   "a\nbb\nccc\n",
+			records:   []string{"a", "bb", "ccc"},
+			committed: 9,
+		},
+		{
+			name:      "partial last line",
+			content:   "a\nbb",
+			records:   []string{"a"},
+			committed: 2,
+		},
+		{
+			name:      "crlf and empty lines",
+			content:   "a\r\n\r\n\nb\n",
+			records:   []string{"a", "b"},
+			committed: 8,
+		},
+		{
+			name:      "overlong line",
+			content:   "abcdefg\n",
+			maxBytes:  3,
+			records:   []string{"abc", "def", "g"},
+			committed: 8,
+		},
+		{
+			name:      "from offset",
+			content:   "a\nbb\nccc\n",
+			offset:    5,
+			records:   []string{"ccc"},
+			committed: 9,
+		},
+		{
+			name:      "offset over size",
+			content:   "a\n",
+			offset:    100,
+			records:   []string{"a"},
+			committed: 2,
+		},
+		{
+			name:      "multiline",
+			content:   "1 start\n  at a\n  at b\n2 next\n",
+			multiline: multiline,
+			records:   []string{"1 start\n  at a\n  at b"},
+			committed: 22,
+		},
+		{
+			name:      "multiline over max bytes",
+			content:   "1 abc\ndef\nghi\n2\n",
+			multiline: multiline,
+			maxBytes:  9,
+			records:   []string{"1 abc\ndef", "ghi"},
+			committed: 14,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewDefaultConfig()
+			conf.Multiline = tt.multiline
+			if tt.maxBytes > 0 {
+				conf.MaxRecordBytes = tt.maxBytes
+			}
+
+			r := openTestReader(t, conf, tt.content, tt.offset)
+
+			var records []string
+			if _, err := r.read(time.Now(), collect(&records)); err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(records, tt.records) {
+				t.Fatalf("read records %q, want %q", records, tt.records)
+			}
+
+			if r.committed != tt.committed {
+				t.Fatalf("committed offset is %d, want %d", r.committed, tt.committed)
+			}
+		})
+	}
+}
+
+func TestReaderMultilineTimeout(t *testing.T) {
+	conf := NewDefaultConfig()
+	conf.Multiline = regexp.MustCompile(`^\d`)
+
+	r := openTestReader(t, conf, "1 start\n  at a\n", 0)
+	now := time.Now()
+
+	var records []string
+	if _, err := r.read(now, collect(&records)); err != nil || len(records) != 0 {
+		t.Fatalf("read returns %q, %v, want the record pending", records, err)
+	}
+
+	if _, err := r.read(now.Add(conf.MultilineTimeout), collect(&records)); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(records, []string{"1 start\n  at a"}) || r.committed != 15 {
+		t.Fatalf("read records %q committed at %d after multiline timeout", records, r.committed)
+	}
+}
+
+func TestReaderFinish(t *testing.T) {
+	conf := NewDefaultConfig()
+	conf.Multiline = regexp.MustCompile(`^\d`)
+
+	r := openTestReader(t, conf, "1 start\n  at a\n2 last", 0)
+
+	var records []string
+	if _, err := r.read(time.Now(), collect(&records)); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	if err := r.finish(time.Now(), collect(&records)); err != nil {
+		t.Fatalf("finish failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(records, []string{"1 start\n  at a", "2 last"}) || r.committed != 21 {
+		t.Fatalf("finished records %q committed at %d", records, r.committed)
+	}
+}
+
+func TestReaderReportFailed(t *testing.T) {
+	r := openTestReader(t, NewDefaultConfig(), "a\nb\n", 0)
+
+	stopping := errors.New("stopping")
+	reported := 0
+
+	_, err := r.read(time.Now(), func([]byte) error {
+		if reported == 1 {
+			return stopping
+		}
+
+		reported++
+
+		return nil
+	})
+	if !errors.Is(err, stopping) {
+		t.Fatalf("read returns %v, want %v", err, stopping)
+	}
+
+	// only the reported record is committed, the tailer resumes from the failed one.
+	if r.committed != 2 {
+		t.Fatalf("committed offset is %d after report failed, want 2", r.committed)
+	}
+}
+
+func TestReaderTruncate(t *testing.T) {
+	r := openTestReader(t, NewDefaultConfig(), "aaa\nbbb\n", 0)
+
+	var records []string
+	if _, err := r.read(time.Now(), collect(&records)); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	if truncated, err := r.checkTruncate(); err != nil || truncated {
+		t.Fatalf("check truncate returns %v, %v before truncated", truncated, err)
+	}
+
+	if err := os.WriteFile(r.path, []byte("c\n"), 0o600); err != nil {
+		t.Fatalf("truncate file failed: %v", err)
+	}
+
+	if truncated, err := r.checkTruncate(); err != nil || !truncated {
+		t.Fatalf("check truncate returns %v, %v after truncated", truncated, err)
+	}
+
+	if _, err := r.read(time.Now(), collect(&records)); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(records, []string{"aaa", "bbb", "c"}) || r.committed != 2 {
+		t.Fatalf("read records %q committed at %d after truncated", records, r.committed)
+	}
+}
@@ -0,0 +1,334 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+// Package agenttail provides a file tailer which reports the records of files through agent data report.
+package agenttail
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	agentreport "github.com/TencentBlueKing/bk-gse-sdk/go/service/agent-report"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// Reporter reports the data to a data-id, see agentreport.Reporter.
+type Reporter = agentreport.Reporter
+
+// Tailer follows the files matching the globs and reports every line or multiline record.
+// the offset of a file only moves forward after the record is reported, a blocking or failing
+// reporter holds the reading, so the tailer resumes exactly from the checkpoint after restart.
+type Tailer interface {
+	// Close stops the tailing and saves the checkpoint, the blocking report is canceled when the context is done.
+	Close(ctx context.Context) error
+}
+
+// New creates a new file tailer which reports the records by reporter, it starts tailing at once.
+func New(reporter Reporter, opts ...OptionFn) (Tailer, error) {
+	conf := NewDefaultConfig()
+
+	for _, opt := range opts {
+		opt(conf)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	offsets, err := loadCheckpoint(conf.CheckpointPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	t := &tailer{
+		conf:     conf,
+		reporter: reporter,
+		offsets:  offsets,
+		readers:  make(map[string]*reader),
+		ctx:      ctx,
+		cancel:   cancel,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	go t.holdTailing()
+
+	return t, nil
+}
+
+type tailer struct {
+	conf *Config
+
+	reporter Reporter
+
+	// offsets are the offsets from checkpoint by file identity, removed once the file is found.
+	offsets map[string]checkpointRecord
+
+	// readers are the tailing files by file identity, only accessed by the tailing goroutine until it's stopped.
+	readers map[string]*reader
+
+	// ctx is canceled when closing is timeout, to cancel the blocking report.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	closed bool
+	mutex  sync.Mutex
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+var errStopping = errors.New("tailer is stopping")
+
+// Close stops the tailing and saves the checkpoint.
+func (t *tailer) Close(ctx context.Context) error {
+	t.mutex.Lock()
+	if t.closed {
+		t.mutex.Unlock()
+		return types.ErrAlreadyTerminated()
+	}
+
+	t.closed = true
+	t.mutex.Unlock()
+
+	close(t.stop)
+
+	select {
+	case <-t.stopped:
+	case <-ctx.Done():
+		t.cancel()
+		<-t.stopped
+	}
+
+	t.cancel()
+
+	err := t.saveCheckpoint()
+
+	for id, r := range t.readers {
+		r.close()
+		delete(t.readers, id)
+	}
+
+	return err
+}
+
+func (t *tailer) holdTailing() {
+	defer close(t.stopped)
+
+	t.conf.Logger.Info("start tailing %v with interval %s", t.conf.Paths, t.conf.PollInterval.String())
+
+	ticker := time.NewTicker(t.conf.PollInterval)
+	defer ticker.Stop()
+
+	savedAt := time.Now()
+
+	for first := true; ; first = false {
+		if err := t.poll(first); errors.Is(err, errStopping) {
+			t.conf.Logger.Info("stop tailing %v", t.conf.Paths)
+			return
+		}
+
+		if t.conf.CheckpointPath != "" && time.Since(savedAt) >= t.conf.CheckpointInterval {
+			if err := t.saveCheckpoint(); err != nil {
+				t.conf.Logger.Warn("save checkpoint %s failed: %v", t.conf.CheckpointPath, err)
+			}
+
+			savedAt = time.Now()
+		}
+
+		select {
+		case <-t.stop:
+			t.conf.Logger.Info("stop tailing %v", t.conf.Paths)
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll finds the files and reads the new records, the rotated files are drained before the others.
+func (t *tailer) poll(first bool) error {
+	now := time.Now()
+	matched := t.find()
+
+	for id, info := range matched {
+		if r, ok := t.readers[id]; ok {
+			r.path = info.path
+			continue
+		}
+
+		t.open(id, info, first)
+	}
+
+	ids := make([]string, 0, len(t.readers))
+	for id := range t.readers {
+		ids = append(ids, id)
+	}
+
+	sort.SliceStable(ids, func(i, j int) bool {
+		_, iMatched := matched[ids[i]]
+		_, jMatched := matched[ids[j]]
+
+		return !iMatched && jMatched
+	})
+
+	for _, id := range ids {
+		r := t.readers[id]
+		_, rotated := matched[id]
+		rotated = !rotated
+
+		if truncated, err := r.checkTruncate(); err != nil {
+			t.conf.Logger.Warn("stat file %s failed: %v", r.path, err)
+		} else if truncated {
+			t.conf.Logger.Info("file %s is truncated, read from the head", r.path)
+		}
+
+		read, err := r.read(now, t.report)
+		if errors.Is(err, errStopping) {
+			return err
+		}
+
+		if err != nil {
+			t.conf.Logger.Warn("read file %s failed: %v", r.path, err)
+		}
+
+		// the rotated file is closed after it keeps idle for one poll, for the writer may still be writing it.
+		if rotated && !read {
+			if err := r.finish(now, t.report); errors.Is(err, errStopping) {
+				return err
+			}
+
+			t.conf.Logger.Info("file %s is rotated or removed, stop tailing it", r.path)
+
+			r.close()
+			delete(t.readers, id)
+		}
+	}
+
+	return nil
+}
+
+type matchedFile struct {
+	path string
+	info os.FileInfo
+}
+
+// find returns the regular files matching the globs by file identity.
+func (t *tailer) find() map[string]matchedFile {
+	matched := make(map[string]matchedFile)
+
+	for _, pattern := range t.conf.Paths {
+		// the pattern is validated, no error here.
+		paths, _ := filepath.Glob(pattern)
+
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+
+			if id := fileID(info); id != "" {
+				matched[id] = matchedFile{path: path, info: info}
+			}
+		}
+	}
+
+	return matched
+}
+
+// open starts tailing the new found file, from the checkpoint offset if any.
+func (t *tailer) open(id string, file matchedFile, first bool) {
+	offset := int64(0)
+
+	if record, ok := t.offsets[id]; ok {
+		offset = record.Offset
+	} else if first && !t.conf.ReadFromHead {
+		offset = file.info.Size()
+	}
+
+	r, err := openReader(t.conf, file.path, file.info, offset)
+	if err != nil {
+		t.conf.Logger.Warn("open file %s failed: %v", file.path, err)
+		return
+	}
+
+	t.conf.Logger.Info("start tailing file %s from offset %d", file.path, r.offset)
+	t.readers[id] = r
+	delete(t.offsets, id)
+}
+
+// report reports the record until succeed, it only fails when the tailer is stopping.
+// the record which could never be reported is dropped.
+func (t *tailer) report(content []byte) error {
+	for {
+		err := t.reporter.ReportData(t.ctx, t.conf.DataID, content)
+		if err == nil || types.IsQueued(err) {
+			return nil
+		}
+
+		if errors.Is(err, types.ErrMessageTooLarge()) || errors.Is(err, types.ErrInvalidTimestamp()) {
+			t.conf.Logger.Warn("drop record of %d bytes which could not be reported: %v", len(content), err)
+			return nil
+		}
+
+		if t.ctx.Err() != nil {
+			return errStopping
+		}
+
+		t.conf.Logger.Warn("report record failed, retry in %s: %v", t.conf.RetryInterval.String(), err)
+
+		select {
+		case <-t.stop:
+			return errStopping
+
+		case <-t.ctx.Done():
+			return errStopping
+
+		case <-time.After(t.conf.RetryInterval):
+		}
+	}
+}
+
+// saveCheckpoint saves the committed offsets of the tailing files, and the offsets from checkpoint of the files
+// not found yet, so a file missing for a while, e.g. failed to open, is still resumed after restart.
+func (t *tailer) saveCheckpoint() error {
+	if t.conf.CheckpointPath == "" {
+		return nil
+	}
+
+	records := make([]checkpointRecord, 0, len(t.readers)+len(t.offsets))
+	for id, r := range t.readers {
+		records = append(records, checkpointRecord{Path: r.path, ID: id, Offset: r.committed})
+	}
+
+	for id, record := range t.offsets {
+		if _, ok := t.readers[id]; !ok {
+			records = append(records, record)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Path < records[j].Path
+	})
+
+	return saveCheckpoint(t.conf.CheckpointPath, records)
+}
@@ -0,0 +1,167 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/server"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// Agent provides agent handling methods.
+// it queries the state and info of the agents only, GSE provides no query of whether the plugin is connected
+// to its agent, a dispatch to such an agent fails in its agent result instead.
+type Agent interface {
+	// ListAgentState returns the state of agents by agent id, the agents unknown to GSE are not included.
+	// the agent id list is split into batches of Config.AgentBatchSize.
+	ListAgentState(ctx context.Context, agentIDList ...string) (map[string]*types.AgentState, error)
+
+	// ListAgentInfo returns the info of agents by agent id in the same way as ListAgentState,
+	// including the version and run mode.
+	ListAgentInfo(ctx context.Context, agentIDList ...string) (map[string]*types.AgentInfo, error)
+
+	// FilterAgents returns the agents in any of the statuses in order, AgentStatusRunning if no status given.
+	// it's used to pre-filter the dispatch targets, the agents unknown to GSE are filtered out.
+	FilterAgents(ctx context.Context, agentIDList []string, statuses ...types.AgentStatus) ([]string, error)
+}
+
+type agentClient struct {
+	*client
+}
+
+// Agent provides agent handling methods.
+func (c *client) Agent() Agent {
+	return &agentClient{client: c}
+}
+
+// ListAgentState returns the state of agents by agent id.
+func (c *agentClient) ListAgentState(ctx context.Context, agentIDList ...string) (map[string]*types.AgentState, error) { // nolint:lll
+	agents := make(map[string]*types.AgentState, len(agentIDList))
+
+	err := c.forEachBatch(agentIDList, func(batch []string) error {
+		resp, err := c.apiClient.Agent().ListAgentState(ctx, &server.ListAgentReq{AgentIDList: batch},
+			c.generateHeaders())
+		if err != nil {
+			return err
+		}
+
+		if resp.Code != CodeSuccess {
+			return newDispatchError(c.conf.ErrorCodes, "", resp.Code, resp.Message)
+		}
+
+		for _, item := range resp.Data {
+			status := types.AgentStatus(item.StatusCode)
+
+			agents[item.AgentID] = &types.AgentState{
+				AgentSimpleInfo: types.AgentSimpleInfo{
+					CloudID: item.CloudID,
+					AgentID: item.AgentID,
+				},
+				StatusCode: status,
+				Status:     status.String(),
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return agents, nil
+}
+
+// ListAgentInfo returns the info of agents by agent id.
+func (c *agentClient) ListAgentInfo(ctx context.Context, agentIDList ...string) (map[string]*types.AgentInfo, error) { // nolint:lll
+	agents := make(map[string]*types.AgentInfo, len(agentIDList))
+
+	err := c.forEachBatch(agentIDList, func(batch []string) error {
+		resp, err := c.apiClient.Agent().ListAgentInfo(ctx, &server.ListAgentReq{AgentIDList: batch},
+			c.generateHeaders())
+		if err != nil {
+			return err
+		}
+
+		if resp.Code != CodeSuccess {
+			return newDispatchError(c.conf.ErrorCodes, "", resp.Code, resp.Message)
+		}
+
+		for _, item := range resp.Data {
+			status := types.AgentStatus(item.StatusCode)
+
+			agents[item.AgentID] = &types.AgentInfo{
+				AgentSimpleInfo: types.AgentSimpleInfo{
+					CloudID: item.CloudID,
+					AgentID: item.AgentID,
+				},
+				Version:    item.Version,
+				RunMode:    item.RunMode,
+				StatusCode: status,
+				Status:     status.String(),
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return agents, nil
+}
+
+// FilterAgents returns the agents in any of the statuses in order.
+func (c *agentClient) FilterAgents(ctx context.Context, agentIDList []string, statuses ...types.AgentStatus) (
+	[]string, error) {
+
+	if len(statuses) == 0 {
+		statuses = []types.AgentStatus{types.AgentStatusRunning}
+	}
+
+	agents, err := c.ListAgentState(ctx, agentIDList...)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]string, 0, len(agents))
+	for _, agentID := range agentIDList {
+		if state, ok := agents[agentID]; ok && slices.Contains(statuses, state.StatusCode) {
+			filtered = append(filtered, agentID)
+		}
+	}
+
+	return filtered, nil
+}
+
+// forEachBatch calls list with the agent id list in batches of Config.AgentBatchSize, it stops at the first error.
+func (c *agentClient) forEachBatch(agentIDList []string, list func(batch []string) error) error {
+	if len(agentIDList) == 0 {
+		return errors.New("agent id list is empty")
+	}
+
+	for start := 0; start < len(agentIDList); start += c.conf.AgentBatchSize {
+		end := min(start+c.conf.AgentBatchSize, len(agentIDList))
+
+		if err := list(agentIDList[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	serverapi "github.com/TencentBlueKing/bk-gse-sdk/go/service/server-api"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/service/server-api/serverapitest"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// newAgentClient creates an agent client of the fake server with the agents, queried in batches of 2.
+func newAgentClient(t *testing.T, agents ...types.AgentInfo) (*serverapitest.Server, serverapi.Agent) {
+	t.Helper()
+
+	fake := serverapitest.NewServer()
+	fake.SetAgents(agents...)
+	t.Cleanup(fake.Close)
+
+	client, err := serverapi.New(
+		serverapi.WithBaseURL(fake.URL),
+		serverapi.WithAgentBatchSize(2),
+		serverapi.WithLogger(types.NewEmptyLogger()),
+	)
+	if err != nil {
+		t.Fatalf("new client failed: %v", err)
+	}
+
+	return fake, client.Agent()
+}
+
+func newAgent(agentID string, status types.AgentStatus) types.AgentInfo {
+	return types.AgentInfo{
+		AgentSimpleInfo: types.AgentSimpleInfo{CloudID: 1, AgentID: agentID},
+		Version:         "2.1.0",
+		RunMode:         1,
+		StatusCode:      status,
+	}
+}
+
+func TestListAgentState(t *testing.T) {
+	fake, agent := newAgentClient(t,
+		newAgent("agent-1", types.AgentStatusRunning), newAgent("agent-2", types.AgentStatusBusy))
+
+	states, err := agent.ListAgentState(context.Background(), "agent-1", "agent-2", "unknown")
+	if err != nil {
+		t.Fatalf("list agent state failed: %v", err)
+	}
+
+	want := map[string]*types.AgentState{
+		"agent-1": {
+			AgentSimpleInfo: types.AgentSimpleInfo{CloudID: 1, AgentID: "agent-1"},
+			StatusCode:      types.AgentStatusRunning,
+			Status:          types.AgentStatusRunning.String(),
+		},
+		"agent-2": {
+			AgentSimpleInfo: types.AgentSimpleInfo{CloudID: 1, AgentID: "agent-2"},
+			StatusCode:      types.AgentStatusBusy,
+			Status:          types.AgentStatusBusy.String(),
+		},
+	}
+
+	if !reflect.DeepEqual(states, want) {
+		t.Fatalf("list agent state returns %+v, want %+v", states, want)
+	}
+
+	if requests := fake.Requests(); len(requests) != 2 {
+		t.Fatalf("%d requests for 3 agents in batches of 2, want 2", len(requests))
+	}
+}
+
+func TestListAgentInfo(t *testing.T) {
+	_, agent := newAgentClient(t, newAgent("agent-1", types.AgentStatusRunning))
+
+	infos, err := agent.ListAgentInfo(context.Background(), "agent-1", "unknown")
+	if err != nil {
+		t.Fatalf("list agent info failed: %v", err)
+	}
+
+	want := newAgent("agent-1", types.AgentStatusRunning)
+	want.Status = types.AgentStatusRunning.String()
+
+	if len(infos) != 1 || !reflect.DeepEqual(infos["agent-1"], &want) {
+		t.Fatalf("list agent info returns %+v, want %+v", infos, want)
+	}
+}
+
+func TestFilterAgents(t *testing.T) {
+	_, agent := newAgentClient(t,
+		newAgent("agent-1", types.AgentStatusRunning),
+		newAgent("agent-2", types.AgentStatusBusy),
+		newAgent("agent-3", types.AgentStatusRunning),
+	)
+
+	agentIDList := []string{"agent-3", "agent-2", "unknown", "agent-1"}
+
+	tests := []struct {
+		name     string
+		statuses []types.AgentStatus
+		want     []string
+	}{
+		{name: "running by default", want: []string{"agent-3", "agent-1"}},
+		{name: "busy", statuses: []types.AgentStatus{types.AgentStatusBusy}, want: []string{"agent-2"}},
+		{name: "none", statuses: []types.AgentStatus{types.AgentStatusDamage}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, err := agent.FilterAgents(context.Background(), agentIDList, tt.statuses...)
+			if err != nil {
+				t.Fatalf("filter agents failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(filtered, tt.want) {
+				t.Fatalf("filter agents returns %v, want %v", filtered, tt.want)
+			}
+		})
+	}
+}
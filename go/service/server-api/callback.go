@@ -0,0 +1,235 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// MessageHandler defines the handler of respond message from agent.
+// the error returned is responded with 500.
+type MessageHandler func(ctx context.Context, message *ClusterPluginRespondMessage) error
+
+const (
+	callbackRouteMessageID = "message_id"
+	callbackRouteType      = "type"
+	callbackRouteDefault   = "default"
+)
+
+// CallbackHandler is an http.Handler of the respond message callbacks from GSE.
+// the message is routed to the handler of its message id first, then the handler of its payload type,
+// then the default handler.
+//
+// responded status codes:
+//   - 200: the message is handled, or it's a chunk waiting for the rest ones.
+//   - 400: the body could not be decoded.
+//   - 401: the request failed in authorization.
+//   - 403: the message failed in envelope verification.
+//   - 404: no handler for the message.
+//   - 405: the method is not POST.
+//   - 413: the body is over the max size.
+//   - 500: the handler failed or panicked.
+type CallbackHandler struct {
+	conf *CallbackConfig
+
+	cluster Cluster
+	decoder ClusterEncoderDecoder
+
+	messageIDs     map[string]MessageHandler
+	types          map[string]MessageHandler
+	defaultHandler MessageHandler
+	mutex          sync.RWMutex
+}
+
+// NewCallbackHandler creates a new callback handler which decodes the messages by the cluster.
+func NewCallbackHandler(cluster Cluster, opts ...CallbackOptionFn) (*CallbackHandler, error) {
+	conf := NewDefaultCallbackConfig()
+
+	for _, opt := range opts {
+		opt(conf)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &CallbackHandler{
+		conf:       conf,
+		cluster:    cluster,
+		decoder:    cluster.EncoderDecoder(),
+		messageIDs: make(map[string]MessageHandler),
+		types:      make(map[string]MessageHandler),
+	}, nil
+}
+
+// HandleMessageID registers the handler of messages with the message id, nil handler removes it.
+func (h *CallbackHandler) HandleMessageID(messageID string, handler MessageHandler) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if handler == nil {
+		delete(h.messageIDs, messageID)
+		return
+	}
+
+	h.messageIDs[messageID] = handler
+}
+
+// HandleType registers the handler of messages with the payload type, nil handler removes it.
+func (h *CallbackHandler) HandleType(payloadType string, handler MessageHandler) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if handler == nil {
+		delete(h.types, payloadType)
+		return
+	}
+
+	h.types[payloadType] = handler
+}
+
+// HandleDefault registers the handler of messages not routed by message id or payload type.
+func (h *CallbackHandler) HandleDefault(handler MessageHandler) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.defaultHandler = handler
+}
+
+// ServeHTTP handles the respond message callback request.
+func (h *CallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result := &CallbackResult{}
+	start := time.Now()
+
+	defer func() {
+		if p := recover(); p != nil {
+			h.conf.Logger.Error("callback handler panicked. message-id: %s, panic: %v\n%s",
+				result.MessageID, p, string(debug.Stack()))
+
+			result.StatusCode = http.StatusInternalServerError
+			result.Err = fmt.Errorf("handler panicked: %v", p)
+			w.WriteHeader(result.StatusCode)
+		}
+
+		result.Duration = time.Since(start)
+
+		if h.conf.OnResult != nil {
+			h.conf.OnResult(result)
+		}
+	}()
+
+	result.StatusCode, result.Err = h.serve(r, result)
+	w.WriteHeader(result.StatusCode)
+
+	if result.Err != nil {
+		h.conf.Logger.Warn("handle callback failed. message-id: %s, agent-id: %s, status: %d, err: %v",
+			result.MessageID, result.AgentID, result.StatusCode, result.Err)
+	}
+}
+
+func (h *CallbackHandler) serve(r *http.Request, result *CallbackResult) (int, error) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	if r.Method != http.MethodPost {
+		return http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method)
+	}
+
+	if h.conf.Authorize != nil {
+		if err := h.conf.Authorize(r); err != nil {
+			return http.StatusUnauthorized, errors.Join(types.ErrNotAthorized(), err)
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.conf.MaxBodyBytes+1))
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if int64(len(body)) > h.conf.MaxBodyBytes {
+		return http.StatusRequestEntityTooLarge, errors.Join(types.ErrMessageTooLarge(),
+			fmt.Errorf("callback body over %d bytes", h.conf.MaxBodyBytes))
+	}
+
+	message, err := h.decoder.DecodePluginRespondMessageCallback(body)
+	if errors.Is(err, types.ErrChunkIncomplete()) {
+		return http.StatusOK, nil
+	}
+
+	if errors.Is(err, types.ErrEnvelopeRejected()) {
+		return http.StatusForbidden, err
+	}
+
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	result.MessageID, result.AgentID = message.MessageID, message.AgentID
+
+	route, handler := h.route(message)
+	if handler == nil {
+		return http.StatusNotFound, fmt.Errorf("no handler for message %s", message.MessageID)
+	}
+
+	result.Route = route
+
+	if err := handler(r.Context(), message); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (h *CallbackHandler) route(message *ClusterPluginRespondMessage) (string, MessageHandler) {
+	h.mutex.RLock()
+	handler, ok := h.messageIDs[message.MessageID]
+	h.mutex.RUnlock()
+
+	if ok {
+		return callbackRouteMessageID, handler
+	}
+
+	if h.conf.TypeFunc != nil {
+		payloadType := h.conf.TypeFunc(message)
+
+		h.mutex.RLock()
+		handler, ok = h.types[payloadType]
+		h.mutex.RUnlock()
+
+		if ok {
+			return callbackRouteType, handler
+		}
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	if h.defaultHandler != nil {
+		return callbackRouteDefault, h.defaultHandler
+	}
+
+	return "", nil
+}
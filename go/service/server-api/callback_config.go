@@ -0,0 +1,146 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// NewDefaultCallbackConfig creates a default configuration for callback handler.
+func NewDefaultCallbackConfig() *CallbackConfig {
+	return &CallbackConfig{
+		MaxBodyBytes: defaultCallbackMaxBodyBytes,
+		TypeFunc:     JSONTypeField("type"),
+		Authorize:    nil,
+		OnResult:     nil,
+		Logger:       types.NewDefaultLogger(defaultLoggerLevel),
+	}
+}
+
+const (
+	defaultCallbackMaxBodyBytes = 1024 * 1024 * 16
+)
+
+// CallbackConfig defines the configuration for callback handler.
+type CallbackConfig struct {
+	// MaxBodyBytes describes the max size in bytes of the callback request body.
+	MaxBodyBytes int64
+
+	// TypeFunc describes how to get the payload type of message to route, nil means no routing by type.
+	TypeFunc func(message *ClusterPluginRespondMessage) string
+
+	// Authorize describes the hook to authorize the callback request before reading body, it's optional.
+	// the request failed in authorization is responded with 401.
+	Authorize func(r *http.Request) error
+
+	// OnResult describes the hook called after every callback request is handled, for metrics, it's optional.
+	OnResult func(result *CallbackResult)
+
+	// Logger describes the logger for callback handler.
+	Logger types.Logger
+}
+
+// Validate validates the configuration.
+func (c CallbackConfig) Validate() error {
+	if c.MaxBodyBytes <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("callback max body bytes is 0"))
+	}
+
+	if c.Logger == nil {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("logger is empty"))
+	}
+
+	return nil
+}
+
+// CallbackResult describes the result of a callback request.
+type CallbackResult struct {
+	// MessageID and AgentID are empty if the body could not be decoded.
+	MessageID string
+	AgentID   string
+
+	// Route describes how the message is routed: "message_id", "type", "default", or empty if not routed.
+	Route string
+
+	// StatusCode is the http status code responded.
+	StatusCode int
+
+	// Duration is the time spent in handling the request.
+	Duration time.Duration
+
+	// Err is the failure, nil if succeed.
+	Err error
+}
+
+// JSONTypeField returns a TypeFunc which gets the payload type from the string field of json object content.
+func JSONTypeField(field string) func(message *ClusterPluginRespondMessage) string {
+	return func(message *ClusterPluginRespondMessage) string {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(message.Content), &fields); err != nil {
+			return ""
+		}
+
+		var typ string
+		if err := json.Unmarshal(fields[field], &typ); err != nil {
+			return ""
+		}
+
+		return typ
+	}
+}
+
+// CallbackOptionFn defines the function type for setting callback handler options.
+type CallbackOptionFn func(*CallbackConfig)
+
+// WithCallbackMaxBodyBytes sets the max size in bytes of the callback request body.
+func WithCallbackMaxBodyBytes(size int64) CallbackOptionFn {
+	return func(c *CallbackConfig) {
+		c.MaxBodyBytes = size
+	}
+}
+
+// WithCallbackTypeFunc sets how to get the payload type of message to route.
+func WithCallbackTypeFunc(typeFunc func(message *ClusterPluginRespondMessage) string) CallbackOptionFn {
+	return func(c *CallbackConfig) {
+		c.TypeFunc = typeFunc
+	}
+}
+
+// WithCallbackAuthorize sets the hook to authorize the callback request.
+func WithCallbackAuthorize(authorize func(r *http.Request) error) CallbackOptionFn {
+	return func(c *CallbackConfig) {
+		c.Authorize = authorize
+	}
+}
+
+// WithCallbackOnResult sets the hook called after every callback request is handled.
+func WithCallbackOnResult(onResult func(result *CallbackResult)) CallbackOptionFn {
+	return func(c *CallbackConfig) {
+		c.OnResult = onResult
+	}
+}
+
+// WithCallbackLogger sets the logger.
+func WithCallbackLogger(logger types.Logger) CallbackOptionFn {
+	return func(c *CallbackConfig) {
+		c.Logger = logger
+	}
+}
@@ -0,0 +1,182 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// newTestCallbackHandler creates a callback handler decoding by a client of no server, results are sent to the channel.
+func newTestCallbackHandler(t *testing.T, opts ...CallbackOptionFn) (*CallbackHandler, <-chan *CallbackResult) {
+	t.Helper()
+
+	c, err := New(WithClusterAuth(1, "token"), WithLogger(types.NewEmptyLogger()))
+	if err != nil {
+		t.Fatalf("new client failed: %v", err)
+	}
+
+	results := make(chan *CallbackResult, 1)
+	opts = append([]CallbackOptionFn{
+		WithCallbackLogger(types.NewEmptyLogger()),
+		WithCallbackOnResult(func(result *CallbackResult) { results <- result }),
+	}, opts...)
+
+	h, err := NewCallbackHandler(c.Cluster(), opts...)
+	if err != nil {
+		t.Fatalf("new callback handler failed: %v", err)
+	}
+
+	return h, results
+}
+
+func TestCallbackHandlerServeHTTP(t *testing.T) {
+	const body = `{"message_id":"msg","bk_agent_id":"agent","content":"{\"type\":\"pong\"}"}`
+
+	errHandler := errors.New("handler failed")
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		header string
+
+		// register registers the handlers.
+		register func(h *CallbackHandler)
+
+		status int
+		route  string
+		err    error
+	}{
+		{name: "method not allowed", method: http.MethodGet, body: body, status: http.StatusMethodNotAllowed},
+		{name: "unauthorized", header: "invalid", body: body, status: http.StatusUnauthorized,
+			err: types.ErrNotAthorized()},
+		{name: "body too large", body: body + strings.Repeat(" ", 1024), status: http.StatusRequestEntityTooLarge,
+			err: types.ErrMessageTooLarge()},
+		{name: "invalid body", body: "{", status: http.StatusBadRequest},
+		{name: "no handler", body: body, status: http.StatusNotFound},
+		{
+			name: "handler failed",
+			body: body,
+			register: func(h *CallbackHandler) {
+				h.HandleDefault(func(context.Context, *ClusterPluginRespondMessage) error { return errHandler })
+			},
+			status: http.StatusInternalServerError,
+			route:  callbackRouteDefault,
+			err:    errHandler,
+		},
+		{
+			name: "routed by message id",
+			body: body,
+			register: func(h *CallbackHandler) {
+				h.HandleMessageID("msg", func(context.Context, *ClusterPluginRespondMessage) error { return nil })
+				h.HandleType("pong", func(context.Context, *ClusterPluginRespondMessage) error { return errHandler })
+			},
+			status: http.StatusOK,
+			route:  callbackRouteMessageID,
+		},
+		{
+			name: "routed by type",
+			body: body,
+			register: func(h *CallbackHandler) {
+				h.HandleType("pong", func(context.Context, *ClusterPluginRespondMessage) error { return nil })
+				h.HandleDefault(func(context.Context, *ClusterPluginRespondMessage) error { return errHandler })
+			},
+			status: http.StatusOK,
+			route:  callbackRouteType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, results := newTestCallbackHandler(t,
+				WithCallbackMaxBodyBytes(int64(len(body))),
+				WithCallbackAuthorize(func(r *http.Request) error {
+					if r.Header.Get("X-Token") != "" {
+						return errors.New("invalid token")
+					}
+
+					return nil
+				}),
+			)
+
+			if tt.register != nil {
+				tt.register(h)
+			}
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+
+			r := httptest.NewRequest(method, "/callback", strings.NewReader(tt.body))
+			if tt.header != "" {
+				r.Header.Set("X-Token", tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("callback responds %d, want %d", w.Code, tt.status)
+			}
+
+			result := <-results
+			if result.StatusCode != tt.status || result.Route != tt.route {
+				t.Fatalf("callback result is %d routed %q, want %d routed %q",
+					result.StatusCode, result.Route, tt.status, tt.route)
+			}
+
+			if (result.Err == nil) != (tt.status == http.StatusOK) {
+				t.Fatalf("callback result error is %v with status %d", result.Err, result.StatusCode)
+			}
+
+			if tt.err != nil && !errors.Is(result.Err, tt.err) {
+				t.Fatalf("callback result error is %v, want %v", result.Err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCallbackHandlerPanic(t *testing.T) {
+	h, results := newTestCallbackHandler(t)
+	h.HandleMessageID("msg", func(context.Context, *ClusterPluginRespondMessage) error {
+		panic("boom")
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/callback",
+		strings.NewReader(`{"message_id":"msg","bk_agent_id":"agent","content":"ping"}`))
+	w := httptest.NewRecorder()
+
+	// the panic is recovered and responded with 500.
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("callback responds %d after panicked, want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	result := <-results
+	if result.StatusCode != http.StatusInternalServerError || result.MessageID != "msg" || result.AgentID != "agent" ||
+		result.Err == nil || !strings.Contains(result.Err.Error(), "boom") {
+		t.Fatalf("unexpected callback result after panicked: %+v", result)
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/chunk"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/envelope"
 	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/server"
 )
 
@@ -27,6 +29,9 @@ import (
 type Client interface {
 	// Cluster provides cluster handling methods.
 	Cluster() Cluster
+
+	// Agent provides agent handling methods.
+	Agent() Agent
 }
 
 // New creates a new client.
@@ -41,12 +46,29 @@ func New(opts ...OptionFn) (Client, error) {
 		return nil, err
 	}
 
-	c := &client{conf: conf}
+	assembler := chunk.NewAssembler(conf.ChunkTimeout, conf.MaxChunkedMessageBytes,
+		conf.MaxPendingChunked, conf.MaxPendingChunkedBytes)
+
+	c := &client{
+		conf:      conf,
+		assembler: assembler,
+	}
+
+	if conf.Envelope.Enabled() {
+		env, err := envelope.New(conf.Envelope)
+		if err != nil {
+			return nil, err
+		}
+
+		c.envelope = env
+	}
 	c.apiClient = server.New(server.Config{
-		BaseHeader: conf.BaseHeader,
-		BaseURL:    conf.BaseURL,
-		Client:     conf.Client,
-		Logger:     conf.Logger,
+		BaseHeader:   conf.BaseHeader,
+		BaseURL:      conf.BaseURL,
+		Client:       conf.Client,
+		Timeout:      conf.RequestTimeout,
+		Interceptors: conf.Interceptors,
+		Logger:       conf.Logger,
 	})
 
 	return c, nil
@@ -56,6 +78,12 @@ type client struct {
 	conf *Config
 
 	apiClient server.Client
+
+	// assembler reassembles the chunked messages from agents.
+	assembler *chunk.Assembler
+
+	// envelope seals the outgoing messages and opens the incoming messages, nil if it's disabled.
+	envelope *envelope.Envelope
 }
 
 // Cluster provides cluster handling methods.
@@ -78,3 +106,21 @@ func (c *client) generateHeaders() http.Header {
 
 	return header
 }
+
+// seal seals the message content if envelope is enabled.
+func (c *client) seal(messageID string, content []byte) ([]byte, error) {
+	if c.envelope == nil {
+		return content, nil
+	}
+
+	return c.envelope.Seal(messageID, content)
+}
+
+// open opens the message content if envelope is enabled.
+func (c *client) open(messageID string, content []byte) ([]byte, error) {
+	if c.envelope == nil {
+		return content, nil
+	}
+
+	return c.envelope.Open(messageID, content)
+}
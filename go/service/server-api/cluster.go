@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/chunk"
 	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/server"
 	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
 )
@@ -29,8 +30,15 @@ type Cluster interface {
 	// DispatchMessage dispatch message to agent through cluster.
 	PluginDispatchMessage(ctx context.Context, messageID string, content []byte, agentIDList ...string) (*ClusterPluginDispatchMessageResp, error) // nolint:lll
 
+	// PluginDispatchMultiMessage dispatches different content to every agent through cluster in one call.
+	// the content received by every agent is FrontContent + its own Content + BackContent.
+	PluginDispatchMultiMessage(ctx context.Context, request *ClusterPluginDispatchMultiMessageReq) (*ClusterPluginDispatchMessageResp, error) // nolint:lll
+
 	// EncoderDecoder provides encoder/decoder of cluster protocol.
 	EncoderDecoder() ClusterEncoderDecoder
+
+	// Codec returns the codec of typed message content.
+	Codec() types.Codec
 }
 
 type clusterClient struct {
@@ -42,39 +50,270 @@ func (c *clusterClient) EncoderDecoder() ClusterEncoderDecoder {
 	return c
 }
 
+// Codec returns the codec of typed message content.
+func (c *clusterClient) Codec() types.Codec {
+	return c.conf.Codec
+}
+
 // PluginDispatchMessage dispatch message to agent through cluster.
 func (c *clusterClient) PluginDispatchMessage(ctx context.Context, messageID string, content []byte, agentIDList ...string) (*ClusterPluginDispatchMessageResp, error) { // nolint:lll
 	if c.conf.Token == "" {
 		return nil, errors.Join(types.ErrInvalidConfig(), errors.New("cluster auth token is empty"))
 	}
 
-	resp, err := c.apiClient.Cluster().DispatchMessage(ctx,
-		&server.ClusterDispatchMessageReq{
-			SlotID:      c.conf.SlotID,
-			Token:       c.conf.Token,
-			MessageID:   messageID,
-			AgentIDList: agentIDList,
-			Content:     string(content),
-		}, c.generateHeaders())
+	content, err := c.seal(messageID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.conf.ChunkSizeBytes == 0 || len(content) <= int(c.conf.ChunkSizeBytes) {
+		return c.dispatchMessage(ctx, messageID, content, agentIDList)
+	}
+
+	return c.dispatchChunkedMessage(ctx, messageID, content, agentIDList)
+}
 
+func (c *clusterClient) dispatchMessage(ctx context.Context, messageID string, content []byte, agentIDList []string) (*ClusterPluginDispatchMessageResp, error) { // nolint:lll
+	return c.dispatchWithRetry(ctx, messageID, agentIDList, func(targets []string) (*ClusterPluginDispatchMessageResp, error) { // nolint:lll
+		resp, err := c.apiClient.Cluster().DispatchMessage(ctx,
+			&server.ClusterDispatchMessageReq{
+				SlotID:      c.conf.SlotID,
+				Token:       c.conf.Token,
+				MessageID:   messageID,
+				AgentIDList: targets,
+				Content:     string(content),
+			}, c.generateHeaders())
+
+		if err != nil {
+			return nil, err
+		}
+
+		return c.newClusterPluginDispatchMessageResp(resp), nil
+	})
+}
+
+// dispatchChunkedMessage dispatches the chunks one by one, the agents failed in any chunk will not
+// receive the rest chunks, and their first failure is kept in the result.
+func (c *clusterClient) dispatchChunkedMessage(ctx context.Context, messageID string, content []byte, agentIDList []string) (*ClusterPluginDispatchMessageResp, error) { // nolint:lll
+	frames, err := chunk.Split(messageID, content, int(c.conf.ChunkSizeBytes))
 	if err != nil {
 		return nil, err
 	}
 
 	result := &ClusterPluginDispatchMessageResp{
-		Code:         resp.Code,
-		Message:      resp.Message,
 		AgentResults: make(map[string]*types.DispatchAgentResult, 0),
+		errorCodes:   c.conf.ErrorCodes,
 	}
 
-	for _, item := range resp.Data.Results {
-		result.AgentResults[item.AgentID] = &types.DispatchAgentResult{
-			AgentID: item.AgentID,
-			Code:    item.Code,
-			Message: item.Message,
+	targets := agentIDList
+	total := uint32(len(frames))
+
+	for i, frame := range frames {
+		resp, err := c.dispatchMessage(ctx, chunk.MessageID(messageID, uint32(i), total), frame, targets)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Code = resp.Code
+		result.Message = resp.Message
+
+		if resp.Code != 0 {
+			return result, nil
+		}
+
+		for agentID, agentResult := range resp.AgentResults {
+			result.AgentResults[agentID] = agentResult
+		}
+
+		targets = make([]string, 0, len(targets))
+		for _, agentID := range agentIDList {
+			if !agentFailed(result.AgentResults, agentID) {
+				targets = append(targets, agentID)
+			}
+		}
+
+		if len(targets) == 0 {
+			break
+		}
+	}
+
+	c.conf.Logger.Debug("dispatched chunked message. message-id: %s, size: %d, chunks: %d",
+		messageID, len(content), total)
+
+	return result, nil
+}
+
+// PluginDispatchMultiMessage dispatches different content to every agent through cluster in one call.
+func (c *clusterClient) PluginDispatchMultiMessage(ctx context.Context, request *ClusterPluginDispatchMultiMessageReq) (*ClusterPluginDispatchMessageResp, error) { // nolint:lll
+	req, err := c.newDispatchMultiMessageReq(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.conf.ChunkSizeBytes != 0 {
+		for _, message := range req.AgentMessageList {
+			if len(req.FrontContent)+len(message.Content)+len(req.BackContent) > int(c.conf.ChunkSizeBytes) {
+				return c.dispatchChunkedMultiMessage(ctx, req)
+			}
+		}
+	}
+
+	return c.dispatchMultiMessage(ctx, req)
+}
+
+// newDispatchMultiMessageReq validates the request and converts it into protocol request.
+// when envelope is enabled, the content of every agent is joined and sealed individually.
+func (c *clusterClient) newDispatchMultiMessageReq(request *ClusterPluginDispatchMultiMessageReq) (*server.ClusterDispatchMultiMessageReq, error) { // nolint:lll
+	if c.conf.Token == "" {
+		return nil, errors.Join(types.ErrInvalidConfig(), errors.New("cluster auth token is empty"))
+	}
+
+	if request == nil || len(request.Messages) == 0 {
+		return nil, errors.New("agent message list is empty")
+	}
+
+	req := &server.ClusterDispatchMultiMessageReq{
+		SlotID:           c.conf.SlotID,
+		Token:            c.conf.Token,
+		MessageID:        request.MessageID,
+		AgentMessageList: make([]*server.ClusterAgentMessage, 0, len(request.Messages)),
+		FrontContent:     string(request.FrontContent),
+		BackContent:      string(request.BackContent),
+	}
+
+	for _, message := range request.Messages {
+		if message == nil || message.AgentID == "" {
+			return nil, errors.New("agent id of agent message is empty")
+		}
+
+		req.AgentMessageList = append(req.AgentMessageList, &server.ClusterAgentMessage{
+			AgentID: message.AgentID,
+			Content: string(message.Content),
+		})
+	}
+
+	if c.envelope == nil {
+		return req, nil
+	}
+
+	for _, message := range req.AgentMessageList {
+		content, err := c.seal(req.MessageID, []byte(req.FrontContent+message.Content+req.BackContent))
+		if err != nil {
+			return nil, err
+		}
+
+		message.Content = string(content)
+	}
+
+	req.FrontContent, req.BackContent = "", ""
+
+	return req, nil
+}
+
+func (c *clusterClient) dispatchMultiMessage(ctx context.Context, req *server.ClusterDispatchMultiMessageReq) (*ClusterPluginDispatchMessageResp, error) { // nolint:lll
+	agentIDList := make([]string, 0, len(req.AgentMessageList))
+	for _, message := range req.AgentMessageList {
+		agentIDList = append(agentIDList, message.AgentID)
+	}
+
+	return c.dispatchWithRetry(ctx, req.MessageID, agentIDList, func(targets []string) (*ClusterPluginDispatchMessageResp, error) { // nolint:lll
+		targetReq := req
+		if len(targets) != len(req.AgentMessageList) {
+			targetReq = filterDispatchMultiMessageReq(req, targets)
+		}
+
+		resp, err := c.apiClient.Cluster().DispatchMultiMessage(ctx, targetReq, c.generateHeaders())
+		if err != nil {
+			return nil, err
+		}
+
+		return c.newClusterPluginDispatchMultiMessageResp(resp), nil
+	})
+}
+
+// filterDispatchMultiMessageReq returns a copy of req which only contains the messages of targets.
+func filterDispatchMultiMessageReq(req *server.ClusterDispatchMultiMessageReq, targets []string) *server.ClusterDispatchMultiMessageReq { // nolint:lll
+	targetSet := make(map[string]struct{}, len(targets))
+	for _, agentID := range targets {
+		targetSet[agentID] = struct{}{}
+	}
+
+	filtered := *req
+	filtered.AgentMessageList = make([]*server.ClusterAgentMessage, 0, len(targets))
+
+	for _, message := range req.AgentMessageList {
+		if _, ok := targetSet[message.AgentID]; ok {
+			filtered.AgentMessageList = append(filtered.AgentMessageList, message)
 		}
 	}
 
+	return &filtered
+}
+
+// dispatchChunkedMultiMessage splits the joined content of every agent into chunks, and dispatches
+// the chunks of the same index in one call. the agents failed in any chunk will not receive the rest chunks.
+func (c *clusterClient) dispatchChunkedMultiMessage(ctx context.Context, req *server.ClusterDispatchMultiMessageReq) (*ClusterPluginDispatchMessageResp, error) { // nolint:lll
+	frames := make(map[string][][]byte, len(req.AgentMessageList))
+	total := 0
+
+	for _, message := range req.AgentMessageList {
+		agentFrames, err := chunk.Split(req.MessageID, []byte(req.FrontContent+message.Content+req.BackContent),
+			int(c.conf.ChunkSizeBytes))
+		if err != nil {
+			return nil, err
+		}
+
+		frames[message.AgentID] = agentFrames
+		total = max(total, len(agentFrames))
+	}
+
+	result := &ClusterPluginDispatchMessageResp{
+		AgentResults: make(map[string]*types.DispatchAgentResult, 0),
+		errorCodes:   c.conf.ErrorCodes,
+	}
+
+	for i := 0; i < total; i++ {
+		chunkReq := &server.ClusterDispatchMultiMessageReq{
+			SlotID:    req.SlotID,
+			Token:     req.Token,
+			MessageID: chunk.MessageID(req.MessageID, uint32(i), uint32(total)),
+		}
+
+		for _, message := range req.AgentMessageList {
+			if agentFailed(result.AgentResults, message.AgentID) || i >= len(frames[message.AgentID]) {
+				continue
+			}
+
+			chunkReq.AgentMessageList = append(chunkReq.AgentMessageList, &server.ClusterAgentMessage{
+				AgentID: message.AgentID,
+				Content: string(frames[message.AgentID][i]),
+			})
+		}
+
+		if len(chunkReq.AgentMessageList) == 0 {
+			break
+		}
+
+		resp, err := c.dispatchMultiMessage(ctx, chunkReq)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Code = resp.Code
+		result.Message = resp.Message
+
+		if resp.Code != 0 {
+			return result, nil
+		}
+
+		for agentID, agentResult := range resp.AgentResults {
+			result.AgentResults[agentID] = agentResult
+		}
+	}
+
+	c.conf.Logger.Debug("dispatched chunked multi message. message-id: %s, agents: %d, chunks: %d",
+		req.MessageID, len(req.AgentMessageList), total)
+
 	return result, nil
 }
 
@@ -86,6 +325,12 @@ type ClusterEncoderDecoder interface {
 	// DecodePluginDispatchMessageResponse decodes the dispatch message response body.
 	DecodePluginDispatchMessageResponse(body []byte) (*ClusterPluginDispatchMessageResp, error)
 
+	// EncodePluginDispatchMultiMessageRequest generates dispatch multi message request body.
+	EncodePluginDispatchMultiMessageRequest(request *ClusterPluginDispatchMultiMessageReq) ([]byte, error)
+
+	// DecodePluginDispatchMultiMessageResponse decodes the dispatch multi message response body.
+	DecodePluginDispatchMultiMessageResponse(body []byte) (*ClusterPluginDispatchMessageResp, error)
+
 	// DecodePluginRespondMessageCallback decodes the respond message callback request body.
 	DecodePluginRespondMessageCallback(body []byte) (*ClusterPluginRespondMessage, error)
 }
@@ -96,6 +341,11 @@ func (c *clusterClient) EncodePluginDispatchMessageRequest(messageID string, con
 		return nil, errors.Join(types.ErrInvalidConfig(), errors.New("cluster auth token is empty"))
 	}
 
+	content, err := c.seal(messageID, content)
+	if err != nil {
+		return nil, err
+	}
+
 	return json.Marshal(&server.ClusterDispatchMessageReq{
 		SlotID:      c.conf.SlotID,
 		Token:       c.conf.Token,
@@ -112,24 +362,33 @@ func (c *clusterClient) DecodePluginDispatchMessageResponse(body []byte) (*Clust
 		return nil, err
 	}
 
-	result := &ClusterPluginDispatchMessageResp{
-		Code:         resp.Code,
-		Message:      resp.Message,
-		AgentResults: make(map[string]*types.DispatchAgentResult, 0),
+	return c.newClusterPluginDispatchMessageResp(resp), nil
+}
+
+// EncodePluginDispatchMultiMessageRequest generates dispatch multi message request body.
+func (c *clusterClient) EncodePluginDispatchMultiMessageRequest(request *ClusterPluginDispatchMultiMessageReq) ([]byte, error) { // nolint:lll
+	req, err := c.newDispatchMultiMessageReq(request)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, item := range resp.Data.Results {
-		result.AgentResults[item.AgentID] = &types.DispatchAgentResult{
-			AgentID: item.AgentID,
-			Code:    item.Code,
-			Message: item.Message,
-		}
+	return json.Marshal(req)
+}
+
+// DecodePluginDispatchMultiMessageResponse decodes the dispatch multi message response body.
+func (c *clusterClient) DecodePluginDispatchMultiMessageResponse(body []byte) (*ClusterPluginDispatchMessageResp, error) { // nolint:lll
+	resp := new(server.ClusterDispatchMultiMessageResp)
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return c.newClusterPluginDispatchMultiMessageResp(resp), nil
 }
 
 // DecodePluginRespondMessageCallback decodes the respond message callback request body.
+// for the chunked message, it returns types.ErrChunkIncomplete for every chunk except the last one,
+// and returns the reassembled message on the last one.
+// when envelope is enabled, the message failed in verification returns types.ErrEnvelopeRejected.
 func (c *clusterClient) DecodePluginRespondMessageCallback(body []byte) (*ClusterPluginRespondMessage, error) {
 	data := new(server.ClusterRespondMessage)
 	if err := json.Unmarshal(body, data); err != nil {
@@ -142,5 +401,52 @@ func (c *clusterClient) DecodePluginRespondMessageCallback(body []byte) (*Cluste
 		Content:   data.Content,
 	}
 
+	content := []byte(data.Content)
+
+	// the chunk frames are always reassembled, no matter whether chunked transfer is enabled in sending.
+	if chunk.IsFrame(content) {
+		header, payload, err := c.assembler.Add(data.AgentID, content)
+		if err != nil {
+			return nil, err
+		}
+
+		result.MessageID = header.MessageID
+		content = payload
+	}
+
+	content, err := c.open(result.MessageID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Content = string(content)
+
 	return result, nil
 }
+
+func (c *clusterClient) newClusterPluginDispatchMessageResp(resp *server.ClusterDispatchMessageResp) *ClusterPluginDispatchMessageResp { // nolint:lll
+	result := &ClusterPluginDispatchMessageResp{
+		Code:         resp.Code,
+		Message:      resp.Message,
+		AgentResults: make(map[string]*types.DispatchAgentResult, 0),
+		errorCodes:   c.conf.ErrorCodes,
+	}
+
+	for _, item := range resp.Data.Results {
+		result.AgentResults[item.AgentID] = &types.DispatchAgentResult{
+			AgentID: item.AgentID,
+			Code:    item.Code,
+			Message: item.Message,
+		}
+	}
+
+	return result
+}
+
+func (c *clusterClient) newClusterPluginDispatchMultiMessageResp(resp *server.ClusterDispatchMultiMessageResp) *ClusterPluginDispatchMessageResp { // nolint:lll
+	return c.newClusterPluginDispatchMessageResp(&server.ClusterDispatchMessageResp{
+		Code:    resp.Code,
+		Message: resp.Message,
+		Data:    resp.Data,
+	})
+}
@@ -0,0 +1,281 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/server"
+	serverapi "github.com/TencentBlueKing/bk-gse-sdk/go/service/server-api"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/service/server-api/serverapitest"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// newChunkedCluster creates a cluster client of the fake server with chunked transfer in chunkSize.
+func newChunkedCluster(t *testing.T, chunkSize uint32) (*serverapitest.Server, serverapi.Cluster) {
+	t.Helper()
+
+	fake := serverapitest.NewServer(serverapitest.WithClusterAuth(collectSlotID, collectToken))
+	t.Cleanup(fake.Close)
+
+	client, err := serverapi.New(
+		serverapi.WithBaseURL(fake.URL),
+		serverapi.WithClusterAuth(collectSlotID, collectToken),
+		serverapi.WithChunkedTransfer(chunkSize, time.Minute),
+		serverapi.WithLogger(types.NewEmptyLogger()),
+	)
+	if err != nil {
+		t.Fatalf("new client failed: %v", err)
+	}
+
+	return fake, client.Cluster()
+}
+
+// respondFrames sends the received contents of agent back through the respond message callback protocol,
+// returns the reassembled message.
+func respondFrames(t *testing.T, cluster serverapi.Cluster, agentID string,
+	requests []*serverapitest.Request) *serverapi.ClusterPluginRespondMessage {
+
+	t.Helper()
+
+	for i, request := range requests {
+		body, err := json.Marshal(&server.ClusterRespondMessage{
+			MessageID: request.MessageID,
+			AgentID:   agentID,
+			Content:   request.Contents[agentID],
+		})
+		if err != nil {
+			t.Fatalf("marshal callback %d failed: %v", i, err)
+		}
+
+		message, err := cluster.EncoderDecoder().DecodePluginRespondMessageCallback(body)
+		if i < len(requests)-1 {
+			if !errors.Is(err, types.ErrChunkIncomplete()) {
+				t.Fatalf("decode callback %d returns %v, want %v", i, err, types.ErrChunkIncomplete())
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("decode last callback failed: %v", err)
+		}
+
+		return message
+	}
+
+	t.Fatal("no callback is decoded")
+
+	return nil
+}
+
+var chunkedPayloads = []struct {
+	name    string
+	payload []byte
+}{
+	{name: "multi-byte characters", payload: []byte("蓝鲸智云管控平台, 分片传输")},
+	{name: "binary", payload: []byte{0xff, 0x00, 0xfe, 0x80, 0xc3, 0x28, 0x0a, 0xe4, 0xbd, 0x00, 0xff}},
+}
+
+func TestChunkedMessageRoundTrip(t *testing.T) {
+	for _, tt := range chunkedPayloads {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, cluster := newChunkedCluster(t, 5)
+
+			if _, err := cluster.PluginDispatchMessage(context.Background(), "message", tt.payload, "a"); err != nil {
+				t.Fatalf("dispatch failed: %v", err)
+			}
+
+			requests := fake.Requests()
+			if len(requests) < 2 {
+				t.Fatalf("dispatched in %d requests, want chunked", len(requests))
+			}
+
+			message := respondFrames(t, cluster, "a", requests)
+			if message.MessageID != "message" || !bytes.Equal([]byte(message.Content), tt.payload) {
+				t.Fatalf("reassembled message %s: %q, want %q", message.MessageID, message.Content, tt.payload)
+			}
+		})
+	}
+}
+
+func TestChunkedMultiMessageRoundTrip(t *testing.T) {
+	for _, tt := range chunkedPayloads {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, cluster := newChunkedCluster(t, 5)
+
+			_, err := cluster.PluginDispatchMultiMessage(context.Background(), &serverapi.ClusterPluginDispatchMultiMessageReq{
+				MessageID:    "message",
+				Messages:     []*serverapi.AgentMessage{{AgentID: "a", Content: tt.payload}},
+				FrontContent: []byte("前"),
+				BackContent:  []byte("后"),
+			})
+			if err != nil {
+				t.Fatalf("dispatch failed: %v", err)
+			}
+
+			want := append(append([]byte("前"), tt.payload...), []byte("后")...)
+
+			message := respondFrames(t, cluster, "a", fake.Requests())
+			if !bytes.Equal([]byte(message.Content), want) {
+				t.Fatalf("reassembled message %q, want %q", message.Content, want)
+			}
+		})
+	}
+}
+
+func TestPluginDispatchMultiMessage(t *testing.T) {
+	fake := serverapitest.NewServer(serverapitest.WithClusterAuth(collectSlotID, collectToken))
+	defer fake.Close()
+
+	client, err := serverapi.New(
+		serverapi.WithBaseURL(fake.URL),
+		serverapi.WithClusterAuth(collectSlotID, collectToken),
+		serverapi.WithRetryPolicy(serverapi.RetryPolicy{
+			MaxAttempts:       2,
+			InitialBackoff:    time.Millisecond,
+			MaxBackoff:        time.Millisecond,
+			Multiplier:        1,
+			RetryOnAgentCodes: []int{codeAgentOffline},
+		}),
+		serverapi.WithLogger(types.NewEmptyLogger()),
+	)
+	if err != nil {
+		t.Fatalf("new client failed: %v", err)
+	}
+
+	// agent b fails in the first attempt, and agent c fails in all attempts.
+	fake.ScriptAgent("b", serverapitest.AgentResult{Code: codeAgentOffline})
+	fake.ScriptAgent("c", serverapitest.AgentResult{Code: codeAgentOffline},
+		serverapitest.AgentResult{Code: codeAgentOffline})
+
+	resp, err := client.Cluster().PluginDispatchMultiMessage(context.Background(),
+		&serverapi.ClusterPluginDispatchMultiMessageReq{
+			MessageID: "message",
+			Messages: []*serverapi.AgentMessage{
+				{AgentID: "a", Content: []byte("1")},
+				{AgentID: "b", Content: []byte("2")},
+				{AgentID: "c", Content: []byte("3")},
+			},
+			FrontContent: []byte("["),
+			BackContent:  []byte("]"),
+		})
+	if err != nil {
+		t.Fatalf("dispatch multi message failed: %v", err)
+	}
+
+	codes := make(map[string]int, len(resp.AgentResults))
+	for agentID, result := range resp.AgentResults {
+		codes[agentID] = result.Code
+	}
+
+	if want := map[string]int{"a": 0, "b": 0, "c": codeAgentOffline}; !reflect.DeepEqual(codes, want) {
+		t.Fatalf("dispatch multi message returns codes %v, want %v", codes, want)
+	}
+
+	// the retry only sends the content of the failed agents.
+	requests := fake.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("%d requests recorded, want 2", len(requests))
+	}
+
+	want := []map[string]string{
+		{"a": "[1]", "b": "[2]", "c": "[3]"},
+		{"b": "[2]", "c": "[3]"},
+	}
+	for i, request := range requests {
+		if !reflect.DeepEqual(request.Contents, want[i]) {
+			t.Fatalf("request %d contents %v, want %v", i, request.Contents, want[i])
+		}
+	}
+}
+
+func TestPluginDispatchMultiMessageInvalid(t *testing.T) {
+	_, cluster := newChunkedCluster(t, 0)
+
+	tests := []struct {
+		name    string
+		request *serverapi.ClusterPluginDispatchMultiMessageReq
+	}{
+		{name: "nil request"},
+		{name: "no message", request: &serverapi.ClusterPluginDispatchMultiMessageReq{MessageID: "message"}},
+		{
+			name: "empty agent id",
+			request: &serverapi.ClusterPluginDispatchMultiMessageReq{
+				MessageID: "message",
+				Messages:  []*serverapi.AgentMessage{{AgentID: "a"}, {Content: []byte("1")}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cluster.PluginDispatchMultiMessage(context.Background(), tt.request); err == nil {
+				t.Fatal("dispatch invalid multi message succeeded")
+			}
+
+			if _, err := cluster.EncoderDecoder().EncodePluginDispatchMultiMessageRequest(tt.request); err == nil {
+				t.Fatal("encode invalid multi message succeeded")
+			}
+		})
+	}
+}
+
+func TestPluginDispatchMultiMessageEncodeDecode(t *testing.T) {
+	_, cluster := newChunkedCluster(t, 0)
+	codec := cluster.EncoderDecoder()
+
+	body, err := codec.EncodePluginDispatchMultiMessageRequest(&serverapi.ClusterPluginDispatchMultiMessageReq{
+		MessageID:    "message",
+		Messages:     []*serverapi.AgentMessage{{AgentID: "a", Content: []byte("1")}},
+		FrontContent: []byte("["),
+		BackContent:  []byte("]"),
+	})
+	if err != nil {
+		t.Fatalf("encode multi message failed: %v", err)
+	}
+
+	var req server.ClusterDispatchMultiMessageReq
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatalf("decode request body failed: %v", err)
+	}
+
+	if req.SlotID != collectSlotID || req.Token != collectToken || req.MessageID != "message" ||
+		req.FrontContent != "[" || req.BackContent != "]" || len(req.AgentMessageList) != 1 ||
+		*req.AgentMessageList[0] != (server.ClusterAgentMessage{AgentID: "a", Content: "1"}) {
+		t.Fatalf("encoded request %s", body)
+	}
+
+	resp, err := codec.DecodePluginDispatchMultiMessageResponse([]byte(`{"code":0,"message":"ok","data":` +
+		`{"results":[{"bk_agent_id":"a","code":0},{"bk_agent_id":"b","code":1001001,"message":"offline"}]}}`))
+	if err != nil {
+		t.Fatalf("decode multi message response failed: %v", err)
+	}
+
+	want := map[string]*types.DispatchAgentResult{
+		"a": {AgentID: "a"},
+		"b": {AgentID: "b", Code: codeAgentOffline, Message: "offline"},
+	}
+	if resp.Code != 0 || !reflect.DeepEqual(resp.AgentResults, want) {
+		t.Fatalf("decoded response %+v", resp)
+	}
+}
@@ -0,0 +1,247 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// CollectState describes the state of an agent in dispatch-and-collect.
+type CollectState int
+
+const (
+	// CollectAnswered means the plugin on agent answered.
+	CollectAnswered CollectState = 0
+
+	// CollectDispatchFailed means the message failed to dispatch to the agent.
+	CollectDispatchFailed CollectState = 1
+
+	// CollectTimedOut means the plugin on agent did not answer before the deadline.
+	CollectTimedOut CollectState = 2
+)
+
+// String returns the name of state.
+func (s CollectState) String() string {
+	switch s {
+	case CollectAnswered:
+		return "answered"
+	case CollectDispatchFailed:
+		return "dispatch_failed"
+	case CollectTimedOut:
+		return "timed_out"
+	default:
+		return fmt.Sprintf("state(%d)", int(s))
+	}
+}
+
+// AgentCollectResult describes the result of an agent in dispatch-and-collect.
+type AgentCollectResult struct {
+	AgentID string
+	State   CollectState
+
+	// Message is the answer of plugin, only in CollectAnswered.
+	Message *ClusterPluginRespondMessage
+
+	// DispatchResult is the dispatch failure, only in CollectDispatchFailed.
+	DispatchResult *types.DispatchAgentResult
+}
+
+// Collection collects the answers of a dispatched message.
+type Collection struct {
+	// MessageID is the message id of the dispatched message and answers.
+	MessageID string
+
+	results chan *AgentCollectResult
+	agents  map[string]*AgentCollectResult
+	pending int
+	done    chan struct{}
+	mutex   sync.Mutex
+
+	// unregister removes the message id from callback handler, it's called before done is closed.
+	unregister func()
+}
+
+// Results returns the stream of agent results, it's closed after all agents are finished.
+// it's buffered for all agents, so it's fine to only call Wait.
+func (c *Collection) Results() <-chan *AgentCollectResult {
+	return c.results
+}
+
+// Done returns a channel which is closed after all agents are finished.
+func (c *Collection) Done() <-chan struct{} {
+	return c.done
+}
+
+// Wait waits until all agents are finished, returns the results by agent id.
+func (c *Collection) Wait() map[string]*AgentCollectResult {
+	<-c.done
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	agents := make(map[string]*AgentCollectResult, len(c.agents))
+	for agentID, result := range c.agents {
+		agents[agentID] = result
+	}
+
+	return agents
+}
+
+// finish records the result of the agent if it's pending, returns false if it's finished already.
+func (c *Collection) finish(result *AgentCollectResult) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.pending == 0 {
+		return false
+	}
+
+	if current, ok := c.agents[result.AgentID]; !ok || current != nil {
+		return false
+	}
+
+	c.agents[result.AgentID] = result
+	c.results <- result
+	c.pending--
+
+	if c.pending == 0 {
+		c.unregister()
+		close(c.results)
+		close(c.done)
+	}
+
+	return true
+}
+
+// expire marks all pending agents as timed out.
+func (c *Collection) expire() {
+	for _, agentID := range c.pendingAgents() {
+		c.finish(&AgentCollectResult{AgentID: agentID, State: CollectTimedOut})
+	}
+}
+
+func (c *Collection) pendingAgents() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	agents := make([]string, 0, c.pending)
+	for agentID, result := range c.agents {
+		if result == nil {
+			agents = append(agents, agentID)
+		}
+	}
+
+	return agents
+}
+
+// Collect dispatches the message to the agents, and collects the answers with the same message id through
+// the callback handler, until all agents are finished, the timeout or the context is done. 0 timeout means
+// waiting until the context is done.
+// the message id should be unique, it's registered in the handler until the collection is finished.
+// only the first answer of every agent is collected, and the answers from other agents are ignored.
+func (h *CallbackHandler) Collect(ctx context.Context, messageID string, content []byte, timeout time.Duration,
+	agentIDList ...string) (*Collection, error) {
+
+	if len(agentIDList) == 0 {
+		return nil, errors.New("agent id list is empty")
+	}
+
+	collection := &Collection{
+		MessageID: messageID,
+		results:   make(chan *AgentCollectResult, len(agentIDList)),
+		agents:    make(map[string]*AgentCollectResult, len(agentIDList)),
+		done:      make(chan struct{}),
+		unregister: func() {
+			h.HandleMessageID(messageID, nil)
+		},
+	}
+
+	for _, agentID := range agentIDList {
+		collection.agents[agentID] = nil
+	}
+
+	collection.pending = len(collection.agents)
+
+	if err := h.register(messageID, func(_ context.Context, message *ClusterPluginRespondMessage) error {
+		collection.finish(&AgentCollectResult{AgentID: message.AgentID, State: CollectAnswered, Message: message})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// register before dispatching, the answers may arrive before the response of dispatching.
+	resp, err := h.cluster.PluginDispatchMessage(ctx, messageID, content, agentIDList...)
+	if err == nil {
+		err = resp.Err()
+	}
+
+	if err != nil {
+		h.HandleMessageID(messageID, nil)
+		return nil, err
+	}
+
+	for _, agentResult := range resp.AgentResults {
+		if agentResult.Code == CodeSuccess {
+			continue
+		}
+
+		collection.finish(&AgentCollectResult{
+			AgentID:        agentResult.AgentID,
+			State:          CollectDispatchFailed,
+			DispatchResult: agentResult,
+		})
+	}
+
+	go func() {
+		var deadline <-chan time.Time
+		if timeout > 0 {
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+
+			deadline = timer.C
+		}
+
+		select {
+		case <-collection.done:
+		case <-deadline:
+		case <-ctx.Done():
+		}
+
+		collection.expire()
+	}()
+
+	return collection, nil
+}
+
+// register registers the handler of messages with the message id, fails if it's registered already.
+func (h *CallbackHandler) register(messageID string, handler MessageHandler) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.messageIDs[messageID]; ok {
+		return fmt.Errorf("message id %s is registered already", messageID)
+	}
+
+	h.messageIDs[messageID] = handler
+
+	return nil
+}
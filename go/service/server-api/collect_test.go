@@ -0,0 +1,206 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	serverapi "github.com/TencentBlueKing/bk-gse-sdk/go/service/server-api"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/service/server-api/serverapitest"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+const (
+	collectSlotID = 1
+	collectToken  = "token"
+
+	// codeAgentOffline is a failure code scripted to the agents.
+	codeAgentOffline = 1001001
+)
+
+// newCollectHandler creates a callback handler on the fake server, whose plugins reply to the agents in answers.
+func newCollectHandler(t *testing.T, answers ...string) (*serverapitest.Server, *serverapi.CallbackHandler) {
+	t.Helper()
+
+	fake := serverapitest.NewServer(
+		serverapitest.WithClusterAuth(collectSlotID, collectToken),
+		serverapitest.WithReply(func(_, agentID, _ string) ([]byte, bool) {
+			for _, answer := range answers {
+				if answer == agentID {
+					return []byte("pong-" + agentID), true
+				}
+			}
+
+			return nil, false
+		}),
+	)
+
+	client, err := serverapi.New(
+		serverapi.WithBaseURL(fake.URL),
+		serverapi.WithClusterAuth(collectSlotID, collectToken),
+		serverapi.WithErrorCode(serverapitest.CodeAuthFailed, serverapi.ErrorCode{Err: types.ErrNotAthorized()}),
+		serverapi.WithLogger(types.NewEmptyLogger()),
+	)
+	if err != nil {
+		t.Fatalf("new client failed: %v", err)
+	}
+
+	handler, err := serverapi.NewCallbackHandler(client.Cluster(),
+		serverapi.WithCallbackLogger(types.NewEmptyLogger()))
+	if err != nil {
+		t.Fatalf("new callback handler failed: %v", err)
+	}
+
+	callback := httptest.NewServer(handler)
+	fake.SetCallbackURL(callback.URL)
+
+	// the fake server waits for the replies in flight, close it before the callback server.
+	t.Cleanup(callback.Close)
+	t.Cleanup(fake.Close)
+
+	return fake, handler
+}
+
+func TestCollect(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers []string
+		offline []string
+		timeout time.Duration
+		states  map[string]serverapi.CollectState
+	}{
+		{
+			name:    "all answered",
+			answers: []string{"a", "b"},
+			states:  map[string]serverapi.CollectState{"a": serverapi.CollectAnswered, "b": serverapi.CollectAnswered},
+		},
+		{
+			name:    "dispatch failed",
+			answers: []string{"a"},
+			offline: []string{"b"},
+			states: map[string]serverapi.CollectState{
+				"a": serverapi.CollectAnswered, "b": serverapi.CollectDispatchFailed,
+			},
+		},
+		{
+			name:    "timed out",
+			answers: []string{"a"},
+			offline: []string{"c"},
+			timeout: 100 * time.Millisecond,
+			states: map[string]serverapi.CollectState{
+				"a": serverapi.CollectAnswered, "b": serverapi.CollectTimedOut, "c": serverapi.CollectDispatchFailed,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, handler := newCollectHandler(t, tt.answers...)
+			for _, agentID := range tt.offline {
+				fake.ScriptAgent(agentID, serverapitest.AgentResult{Code: codeAgentOffline})
+			}
+
+			agents := make([]string, 0, len(tt.states))
+			for agentID := range tt.states {
+				agents = append(agents, agentID)
+			}
+
+			collection, err := handler.Collect(context.Background(), "message", []byte("ping"), tt.timeout, agents...)
+			if err != nil {
+				t.Fatalf("collect failed: %v", err)
+			}
+
+			streamed := make(map[string]serverapi.CollectState)
+			for result := range collection.Results() {
+				streamed[result.AgentID] = result.State
+			}
+
+			if !reflect.DeepEqual(streamed, tt.states) {
+				t.Fatalf("streamed states %v, want %v", streamed, tt.states)
+			}
+
+			for agentID, result := range collection.Wait() {
+				switch result.State {
+				case serverapi.CollectAnswered:
+					if result.Message.Content != "pong-"+agentID {
+						t.Fatalf("agent %s answered %q", agentID, result.Message.Content)
+					}
+
+				case serverapi.CollectDispatchFailed:
+					if result.DispatchResult.Code != codeAgentOffline {
+						t.Fatalf("agent %s failed in code %d, want %d",
+							agentID, result.DispatchResult.Code, codeAgentOffline)
+					}
+				}
+			}
+
+			// the message id is unregistered after the collection is finished.
+			if err := fake.Respond(context.Background(), "message", "a", []byte("late")); err == nil {
+				t.Fatal("late answer is handled after the collection is finished")
+			}
+		})
+	}
+}
+
+func TestCollectContextDone(t *testing.T) {
+	_, handler := newCollectHandler(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	collection, err := handler.Collect(ctx, "message", []byte("ping"), 0, "a")
+	if err != nil {
+		t.Fatalf("collect failed: %v", err)
+	}
+
+	cancel()
+
+	if result := collection.Wait()["a"]; result.State != serverapi.CollectTimedOut {
+		t.Fatalf("agent state is %v after context done, want %v", result.State, serverapi.CollectTimedOut)
+	}
+}
+
+func TestCollectRequestFailed(t *testing.T) {
+	fake, handler := newCollectHandler(t, "a")
+	fake.ScriptResponses(serverapitest.Response{Code: serverapitest.CodeAuthFailed, Message: "invalid token"})
+
+	if _, err := handler.Collect(context.Background(), "message", nil, 0, "a"); !errors.Is(err, types.ErrNotAthorized()) {
+		t.Fatalf("collect returns %v, want %v", err, types.ErrNotAthorized())
+	}
+
+	// the message id is unregistered after the failure, so it's fine to collect again.
+	ctx, cancel := context.WithCancel(context.Background())
+
+	collection, err := handler.Collect(ctx, "message", nil, 0, "b")
+	if err != nil {
+		t.Fatalf("collect again failed: %v", err)
+	}
+
+	// the message id is registered until the collection is finished.
+	if _, err := handler.Collect(context.Background(), "message", nil, 0, "a"); err == nil {
+		t.Fatal("collect with registered message id succeeded")
+	}
+
+	cancel()
+
+	if result := collection.Wait()["b"]; result.State != serverapi.CollectTimedOut {
+		t.Fatalf("agent state is %v, want %v", result.State, serverapi.CollectTimedOut)
+	}
+}
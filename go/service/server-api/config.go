@@ -18,6 +18,7 @@ package serverapi
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
 )
@@ -29,11 +30,28 @@ func NewDefaultConfig() *Config {
 		BaseURL:    "",
 		Client:     &http.Client{},
 		Logger:     types.NewDefaultLogger(defaultLoggerLevel),
+		Codec:      types.NewJSONCodec(),
+
+		ChunkSizeBytes:         0,
+		ChunkTimeout:           defaultChunkTimeout,
+		MaxChunkedMessageBytes: defaultMaxChunkedMessageBytes,
+		MaxPendingChunked:      defaultMaxPendingChunked,
+		MaxPendingChunkedBytes: defaultMaxPendingChunkedBytes,
+
+		ErrorCodes:     nil,
+		AgentBatchSize: defaultAgentBatchSize,
 	}
 }
 
 const (
 	defaultLoggerLevel = 1 // INFO
+
+	defaultChunkTimeout           = 60 * time.Second
+	defaultMaxChunkedMessageBytes = 1024 * 1024 * 256
+	defaultMaxPendingChunked      = 64
+	defaultMaxPendingChunkedBytes = 1024 * 1024 * 512
+
+	defaultAgentBatchSize = 1000
 )
 
 // Config describes the server configuration.
@@ -47,6 +65,10 @@ type Config struct {
 	// Client is the HTTP client to use for requests.
 	Client *http.Client
 
+	// RequestTimeout is the timeout of every request to server including reading the response,
+	// every retry has its own timeout. 0 means no timeout except the context of the call.
+	RequestTimeout time.Duration
+
 	// SlotID, Token provides the cluster dispatching authentication.
 	SlotID int
 	Token  string
@@ -57,6 +79,40 @@ type Config struct {
 
 	// Logger is the logger to use for requests.
 	Logger types.Logger
+
+	// Codec is the codec of typed message content, default in json.
+	Codec types.Codec
+
+	// Envelope is the signing and encryption envelope of message content shared with plugins.
+	Envelope types.EnvelopeConfig
+
+	// ChunkSizeBytes is the max content size in bytes of every chunk in chunked transfer.
+	// the message larger than it will be split into chunks, 0 means chunked transfer is disabled.
+	// the chunk is base64 encoded in frame, so the frame is about 4/3 of it plus the chunk header.
+	ChunkSizeBytes uint32
+
+	// ChunkTimeout is how long to wait for all chunks of a chunked message before dropping it.
+	ChunkTimeout time.Duration
+
+	// MaxChunkedMessageBytes is the max size in bytes of a reassembled chunked message.
+	MaxChunkedMessageBytes uint64
+
+	// MaxPendingChunked and MaxPendingChunkedBytes is the max number and total received bytes of
+	// the partial chunked messages, the oldest ones are dropped when over the limits.
+	MaxPendingChunked      int
+	MaxPendingChunkedBytes uint64
+
+	// RetryPolicy is the retry policy of dispatching, nil means no retry.
+	RetryPolicy *RetryPolicy
+
+	// ErrorCodes is the classification of GSE codes in responses, the codes not in it are unclassified.
+	ErrorCodes map[int]ErrorCode
+
+	// AgentBatchSize is the max number of agents in every agent state or info query.
+	AgentBatchSize int
+
+	// Interceptors is the interceptor chain of every request to server, the first one is the outermost.
+	Interceptors []Interceptor
 }
 
 // Validate validates the configuration.
@@ -65,9 +121,40 @@ func (c Config) Validate() error {
 		return errors.Join(types.ErrInvalidConfig(), errors.New("http client is nil"))
 	}
 
+	if c.RequestTimeout < 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("request timeout is negative"))
+	}
+
 	if c.Logger == nil {
 		return errors.Join(types.ErrInvalidConfig(), errors.New("logger is empty"))
 	}
 
+	if c.Codec == nil {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("codec is empty"))
+	}
+
+	if c.ChunkTimeout == 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("chunk timeout is 0"))
+	}
+
+	if c.MaxPendingChunked <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("max pending chunked messages is 0"))
+	}
+
+	if c.MaxPendingChunkedBytes < c.MaxChunkedMessageBytes {
+		return errors.Join(types.ErrInvalidConfig(),
+			errors.New("max pending chunked bytes is under max chunked message size"))
+	}
+
+	if c.AgentBatchSize <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("agent batch size is 0"))
+	}
+
+	if c.RetryPolicy != nil {
+		if err := c.RetryPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
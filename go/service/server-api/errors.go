@@ -0,0 +1,137 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// CodeSuccess is the code of succeeded request and agent result in GSE cluster api.
+const CodeSuccess = 0
+
+// ErrorCode describes the classification of a GSE code.
+// the failure codes differ between GSE deployments, so the SDK ships no classification by default,
+// the callers register the codes from their GSE api reference by WithErrorCode.
+type ErrorCode struct {
+	// Err is the error the code maps to, e.g. types.ErrAgentOffline(), nil means no classification.
+	Err error
+
+	// Retryable describes whether the failure is transient and the request could be retried.
+	Retryable bool
+}
+
+// DispatchError describes a failure code from GSE, of the whole request or a single agent.
+// it unwraps to the classified error of code, so it's fine to check it by errors.Is, e.g.
+// errors.Is(err, types.ErrAgentOffline()).
+type DispatchError struct {
+	// AgentID is the agent failed, empty if the whole request failed.
+	AgentID string
+
+	Code    int
+	Message string
+
+	// Err is the classified error of code, nil if the code is not registered.
+	Err error
+
+	// Retryable describes whether the failure is transient and the request could be retried.
+	Retryable bool
+}
+
+// Error returns the error message.
+func (e *DispatchError) Error() string {
+	if e.AgentID != "" {
+		return fmt.Sprintf("dispatch to agent %s failed, code: %d, message: %s", e.AgentID, e.Code, e.Message)
+	}
+
+	return fmt.Sprintf("dispatch failed, code: %d, message: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the classified error of code.
+func (e *DispatchError) Unwrap() error {
+	return e.Err
+}
+
+// newDispatchError creates the *DispatchError of code, classified by codes registered, nil codes classify nothing.
+func newDispatchError(codes map[int]ErrorCode, agentID string, code int, message string) *DispatchError {
+	class := codes[code]
+
+	return &DispatchError{
+		AgentID:   agentID,
+		Code:      code,
+		Message:   message,
+		Err:       class.Err,
+		Retryable: class.Retryable,
+	}
+}
+
+// Err returns the failure of the whole request as *DispatchError, nil if it succeeded.
+func (r *ClusterPluginDispatchMessageResp) Err() error {
+	if r.Code == CodeSuccess {
+		return nil
+	}
+
+	return newDispatchError(r.errorCodes, "", r.Code, r.Message)
+}
+
+// FailedAgents returns the failures of agents as *DispatchError by agent id, the agents not in it succeeded.
+func (r *ClusterPluginDispatchMessageResp) FailedAgents() map[string]error {
+	agents := make(map[string]error, len(r.AgentResults))
+	for agentID, result := range r.AgentResults {
+		if result.Code == CodeSuccess {
+			continue
+		}
+
+		agents[agentID] = newDispatchError(r.errorCodes, agentID, result.Code, result.Message)
+	}
+
+	return agents
+}
+
+// agentFailed returns true if the agent is in results with a failure code,
+// the agents absent or in CodeSuccess succeeded.
+func agentFailed(results map[string]*types.DispatchAgentResult, agentID string) bool {
+	result, ok := results[agentID]
+
+	return ok && result.Code != CodeSuccess
+}
+
+// IsRetryable returns true if the request failed in err could be retried, including the retryable codes,
+// http status 429 and 5xx, and network errors. the canceled context is not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, types.ErrContextDone()) {
+		return false
+	}
+
+	var dispatchErr *DispatchError
+	if errors.As(err, &dispatchErr) {
+		return dispatchErr.Retryable
+	}
+
+	var statusErr *types.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
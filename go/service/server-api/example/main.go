@@ -21,7 +21,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"time"
@@ -64,31 +63,23 @@ type Server struct {
 	client serverapi.Client
 }
 
-// HTTPCallbackHandler handle the respond message callback from agent through cluster.
-func (s *Server) HTTPCallbackHandler(resp http.ResponseWriter, req *http.Request) {
-	defer func() {
-		_ = req.Body.Close()
-	}()
-
-	data, err := io.ReadAll(req.Body)
-	if err != nil {
-		panic(err)
-	}
-
-	message, err := s.client.Cluster().EncoderDecoder().DecodePluginRespondMessageCallback(data)
-	if err != nil {
-		panic(err)
-	}
-
+// HandleRespondMessage handle the respond message callback from agent through cluster.
+func (s *Server) HandleRespondMessage(_ context.Context, message *serverapi.ClusterPluginRespondMessage) error {
 	fmt.Printf("[%s] received message from agent(%s): %s\n",
 		message.MessageID, message.AgentID, message.Content)
 
-	resp.WriteHeader(http.StatusOK)
+	return nil
 }
 
 // ServeHTTPServer serve http server.
 func (s *Server) ServeHTTPServer() {
-	http.HandleFunc("/callback", s.HTTPCallbackHandler)
+	handler, err := serverapi.NewCallbackHandler(s.client.Cluster())
+	if err != nil {
+		panic(err)
+	}
+
+	handler.HandleDefault(s.HandleRespondMessage)
+	http.Handle("/callback", handler)
 
 	if err := http.ListenAndServe(config.ListenAddr, nil); err != nil { // nolint:gosec
 		panic(err)
@@ -127,14 +118,18 @@ func run() {
 			panic(err)
 		}
 
-		if resp.Code != 0 {
-			panic(fmt.Errorf("[%s] send message to agent failed, code: %d, message: %s", messageID, resp.Code, resp.Message))
+		if err = resp.Err(); err != nil {
+			panic(fmt.Errorf("[%s] send message to agent failed: %w", messageID, err))
 		}
 
-		for agentID, agentResult := range resp.AgentResults {
-			err = errors.Join(err,
-				fmt.Errorf("[%s] send message to agent failed, agent-id: %s, agent-code: %d, agent-message: %s",
-					messageID, agentID, agentResult.Code, agentResult.Message))
+		for agentID, agentErr := range resp.FailedAgents() {
+			// the offline agents will receive nothing until they are online again, skip them.
+			if errors.Is(agentErr, types.ErrAgentOffline()) {
+				fmt.Printf("[%s] skip offline agent(%s)\n", messageID, agentID)
+				continue
+			}
+
+			err = errors.Join(err, fmt.Errorf("[%s] %w", messageID, agentErr))
 		}
 		if err != nil {
 			panic(err)
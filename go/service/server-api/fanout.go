@@ -0,0 +1,303 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// DispatchCodeBatchFailed is the agent result code in fan-out dispatching when the whole call of its batch failed
+// without a code from GSE, e.g. network error or canceled context.
+const DispatchCodeBatchFailed = -1
+
+// FanoutBatchResult describes the result of a batch in fan-out dispatching.
+type FanoutBatchResult struct {
+	// Index is the index of batch, starts from 0.
+	Index int
+
+	// AgentIDList is the agents in the batch.
+	AgentIDList []string
+
+	// Resp is the response of the batch, nil if the call failed without response.
+	Resp *ClusterPluginDispatchMessageResp
+
+	// Err is the failure of the whole batch, including the response with non-zero code.
+	Err error
+}
+
+// FanoutProgress describes the progress of fan-out dispatching.
+type FanoutProgress struct {
+	MessageID string
+
+	// TotalBatches, DoneBatches, FailedBatches counts the batches, the failed ones are included in done ones.
+	TotalBatches  int
+	DoneBatches   int
+	FailedBatches int
+
+	// TotalAgents, DoneAgents, FailedAgents counts the agents, the failed ones are included in done ones.
+	TotalAgents  int
+	DoneAgents   int
+	FailedAgents int
+
+	// Batch is the batch just finished.
+	Batch *FanoutBatchResult
+}
+
+// FanoutError describes the failed batches in fan-out dispatching.
+type FanoutError struct {
+	// TotalBatches is the number of all batches.
+	TotalBatches int
+
+	// Batches is the failed batches ordered by index.
+	Batches []*FanoutBatchResult
+}
+
+// Error returns the error message.
+func (e *FanoutError) Error() string {
+	return fmt.Sprintf("%d of %d batches failed, first: %v", len(e.Batches), e.TotalBatches, e.Batches[0].Err)
+}
+
+// Unwrap returns the errors of failed batches.
+func (e *FanoutError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Batches))
+	for _, batch := range e.Batches {
+		errs = append(errs, batch.Err)
+	}
+
+	return errs
+}
+
+// Fanout splits a large agent list into batches, and dispatches the batches with bounded concurrency.
+// all batches use the same message id.
+type Fanout struct {
+	conf *FanoutConfig
+
+	cluster Cluster
+}
+
+// NewFanout creates a new fan-out dispatcher which dispatches through the cluster.
+func NewFanout(cluster Cluster, opts ...FanoutOptionFn) (*Fanout, error) {
+	conf := NewDefaultFanoutConfig()
+
+	for _, opt := range opts {
+		opt(conf)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Fanout{conf: conf, cluster: cluster}, nil
+}
+
+// PluginDispatchMessage dispatches the message to the agents in batches, and merges the results of all batches.
+// the agents in the failed batches are in AgentResults with the code of the batch, or DispatchCodeBatchFailed
+// if there is no code, and a *FanoutError is returned with the merged response, whose Code is always 0.
+// the batches not started are failed with types.ErrContextDone if the context is done.
+func (f *Fanout) PluginDispatchMessage(ctx context.Context, messageID string, content []byte,
+	agentIDList ...string) (*ClusterPluginDispatchMessageResp, error) {
+
+	return f.run(ctx, messageID, len(agentIDList), func(start, end int) []string {
+		return agentIDList[start:end]
+	}, func(start, end int) (*ClusterPluginDispatchMessageResp, error) {
+		return f.cluster.PluginDispatchMessage(ctx, messageID, content, agentIDList[start:end]...)
+	})
+}
+
+// PluginDispatchMultiMessage dispatches different content to every agent in batches, and merges the results
+// of all batches in the same way as PluginDispatchMessage.
+func (f *Fanout) PluginDispatchMultiMessage(ctx context.Context, request *ClusterPluginDispatchMultiMessageReq) (
+	*ClusterPluginDispatchMessageResp, error) {
+
+	if request == nil || len(request.Messages) == 0 {
+		return nil, errors.New("agent message list is empty")
+	}
+
+	return f.run(ctx, request.MessageID, len(request.Messages), func(start, end int) []string {
+		agentIDList := make([]string, 0, end-start)
+		for _, message := range request.Messages[start:end] {
+			if message != nil {
+				agentIDList = append(agentIDList, message.AgentID)
+			}
+		}
+
+		return agentIDList
+	}, func(start, end int) (*ClusterPluginDispatchMessageResp, error) {
+		batch := *request
+		batch.Messages = request.Messages[start:end]
+
+		return f.cluster.PluginDispatchMultiMessage(ctx, &batch)
+	})
+}
+
+// fanoutState keeps the states of a fan-out dispatching.
+type fanoutState struct {
+	conf *FanoutConfig
+
+	result   *ClusterPluginDispatchMessageResp
+	failed   []*FanoutBatchResult
+	progress FanoutProgress
+	mutex    sync.Mutex
+
+	// pending keeps the progress snapshots not reported yet, notifying is true while a batch is reporting them.
+	pending   []FanoutProgress
+	notifying bool
+}
+
+// run dispatches the total agents in batches of [start, end).
+func (f *Fanout) run(ctx context.Context, messageID string, total int, agents func(start, end int) []string,
+	dispatch func(start, end int) (*ClusterPluginDispatchMessageResp, error)) (*ClusterPluginDispatchMessageResp, error) {
+
+	batches := (total + f.conf.BatchSize - 1) / f.conf.BatchSize
+
+	state := &fanoutState{
+		conf: f.conf,
+		result: &ClusterPluginDispatchMessageResp{
+			AgentResults: make(map[string]*types.DispatchAgentResult),
+		},
+		progress: FanoutProgress{MessageID: messageID, TotalBatches: batches, TotalAgents: total},
+	}
+
+	sem := make(chan struct{}, f.conf.Concurrency)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < batches; i++ {
+		start, end := i*f.conf.BatchSize, min((i+1)*f.conf.BatchSize, total)
+		batch := &FanoutBatchResult{Index: i, AgentIDList: agents(start, end)}
+
+		select {
+		case <-ctx.Done():
+			batch.Err = errors.Join(types.ErrContextDone(), ctx.Err())
+			state.done(batch)
+
+			continue
+
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			batch.Resp, batch.Err = dispatch(start, end)
+			state.done(batch)
+		}()
+	}
+
+	wg.Wait()
+
+	f.conf.Logger.Debug("fanout dispatched message. message-id: %s, agents: %d, batches: %d, failed batches: %d",
+		messageID, total, batches, len(state.failed))
+
+	if len(state.failed) == 0 {
+		return state.result, nil
+	}
+
+	slices.SortFunc(state.failed, func(a, b *FanoutBatchResult) int {
+		return a.Index - b.Index
+	})
+
+	return state.result, &FanoutError{TotalBatches: batches, Batches: state.failed}
+}
+
+// done merges the result of batch, and reports the progress.
+func (f *fanoutState) done(batch *FanoutBatchResult) {
+	if batch.Err == nil {
+		batch.Err = batch.Resp.Err()
+	}
+
+	f.merge(batch)
+	f.notify()
+}
+
+// merge merges the result of batch, and keeps the progress snapshot to report.
+func (f *fanoutState) merge(batch *FanoutBatchResult) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.progress.DoneBatches++
+	f.progress.DoneAgents += len(batch.AgentIDList)
+	f.progress.Batch = batch
+
+	if batch.Err != nil {
+		f.fail(batch)
+	} else {
+		f.result.errorCodes = batch.Resp.errorCodes
+
+		for agentID, agentResult := range batch.Resp.AgentResults {
+			f.result.AgentResults[agentID] = agentResult
+
+			if agentResult.Code != CodeSuccess {
+				f.progress.FailedAgents++
+			}
+		}
+	}
+
+	if f.conf.OnProgress != nil {
+		f.pending = append(f.pending, f.progress)
+	}
+}
+
+// notify reports the pending progress snapshots in order out of the lock. only one batch reports at a time,
+// the others leave their snapshots to it, so the hook calls are serialized.
+func (f *fanoutState) notify() {
+	f.mutex.Lock()
+	if f.notifying {
+		f.mutex.Unlock()
+		return
+	}
+
+	f.notifying = true
+
+	for len(f.pending) != 0 {
+		progress := f.pending[0]
+		f.pending = f.pending[1:]
+		f.mutex.Unlock()
+
+		f.conf.OnProgress(&progress)
+
+		f.mutex.Lock()
+	}
+
+	f.notifying = false
+	f.mutex.Unlock()
+}
+
+// fail marks all agents in the failed batch as failed.
+func (f *fanoutState) fail(batch *FanoutBatchResult) {
+	code, message := DispatchCodeBatchFailed, batch.Err.Error()
+	if batch.Resp != nil {
+		code, message = batch.Resp.Code, batch.Resp.Message
+	}
+
+	for _, agentID := range batch.AgentIDList {
+		f.result.AgentResults[agentID] = &types.DispatchAgentResult{AgentID: agentID, Code: code, Message: message}
+	}
+
+	f.progress.FailedBatches++
+	f.progress.FailedAgents += len(batch.AgentIDList)
+	f.failed = append(f.failed, batch)
+}
@@ -0,0 +1,101 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"errors"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// NewDefaultFanoutConfig creates a default configuration for fan-out dispatcher.
+func NewDefaultFanoutConfig() *FanoutConfig {
+	return &FanoutConfig{
+		BatchSize:   defaultFanoutBatchSize,
+		Concurrency: defaultFanoutConcurrency,
+		OnProgress:  nil,
+		Logger:      types.NewDefaultLogger(defaultLoggerLevel),
+	}
+}
+
+const (
+	defaultFanoutBatchSize   = 1000
+	defaultFanoutConcurrency = 4
+)
+
+// FanoutConfig defines the configuration for fan-out dispatcher.
+type FanoutConfig struct {
+	// BatchSize describes the max number of agents in every dispatching call.
+	BatchSize int
+
+	// Concurrency describes the max number of dispatching calls running at the same time.
+	Concurrency int
+
+	// OnProgress describes the hook called after every batch is finished, it's optional.
+	// the calls are serialized, so it's safe to update the states without lock.
+	OnProgress func(progress *FanoutProgress)
+
+	// Logger describes the logger for fan-out dispatcher.
+	Logger types.Logger
+}
+
+// Validate validates the configuration.
+func (c FanoutConfig) Validate() error {
+	if c.BatchSize <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("fanout batch size is 0"))
+	}
+
+	if c.Concurrency <= 0 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("fanout concurrency is 0"))
+	}
+
+	if c.Logger == nil {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("logger is empty"))
+	}
+
+	return nil
+}
+
+// FanoutOptionFn defines the function type for setting fan-out dispatcher options.
+type FanoutOptionFn func(*FanoutConfig)
+
+// WithFanoutBatchSize sets the max number of agents in every dispatching call.
+func WithFanoutBatchSize(size int) FanoutOptionFn {
+	return func(c *FanoutConfig) {
+		c.BatchSize = size
+	}
+}
+
+// WithFanoutConcurrency sets the max number of dispatching calls running at the same time.
+func WithFanoutConcurrency(concurrency int) FanoutOptionFn {
+	return func(c *FanoutConfig) {
+		c.Concurrency = concurrency
+	}
+}
+
+// WithFanoutProgress sets the hook called after every batch is finished.
+func WithFanoutProgress(onProgress func(progress *FanoutProgress)) FanoutOptionFn {
+	return func(c *FanoutConfig) {
+		c.OnProgress = onProgress
+	}
+}
+
+// WithFanoutLogger sets the logger.
+func WithFanoutLogger(logger types.Logger) FanoutOptionFn {
+	return func(c *FanoutConfig) {
+		c.Logger = logger
+	}
+}
@@ -0,0 +1,221 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// fakeCluster responds the dispatching by the scripted results of the first agent in every batch.
+type fakeCluster struct {
+	Cluster
+
+	results map[string]dispatchResult
+
+	batches [][]string
+	mutex   sync.Mutex
+}
+
+func (c *fakeCluster) PluginDispatchMessage(ctx context.Context, messageID string, content []byte,
+	agentIDList ...string) (*ClusterPluginDispatchMessageResp, error) {
+
+	c.mutex.Lock()
+	c.batches = append(c.batches, agentIDList)
+	c.mutex.Unlock()
+
+	if ctx.Err() != nil {
+		return nil, errors.Join(types.ErrContextDone(), ctx.Err())
+	}
+
+	if result, ok := c.results[agentIDList[0]]; ok {
+		return result.resp, result.err
+	}
+
+	codes := make(map[string]int, len(agentIDList))
+	for _, agentID := range agentIDList {
+		codes[agentID] = CodeSuccess
+	}
+
+	return &ClusterPluginDispatchMessageResp{AgentResults: agentResults(codes)}, nil
+}
+
+func TestFanoutPluginDispatchMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  map[string]dispatchResult
+		codes    map[string]int
+		failed   []int
+		progress FanoutProgress
+	}{
+		{
+			name:     "success",
+			codes:    map[string]int{"a": 0, "b": 0, "c": 0, "d": 0, "e": 0},
+			progress: FanoutProgress{TotalBatches: 3, DoneBatches: 3, TotalAgents: 5, DoneAgents: 5},
+		},
+		{
+			name: "agent failed",
+			results: map[string]dispatchResult{
+				"c": {resp: &ClusterPluginDispatchMessageResp{AgentResults: agentResults(map[string]int{
+					"c": codeAgentOffline, "d": CodeSuccess,
+				})}},
+			},
+			codes: map[string]int{"a": 0, "b": 0, "c": codeAgentOffline, "d": 0, "e": 0},
+			progress: FanoutProgress{
+				TotalBatches: 3, DoneBatches: 3, TotalAgents: 5, DoneAgents: 5, FailedAgents: 1,
+			},
+		},
+		{
+			name: "batch failed",
+			results: map[string]dispatchResult{
+				"a": {resp: &ClusterPluginDispatchMessageResp{Code: codeAuthFailed, Message: "auth failed"}},
+				"e": {err: io.ErrUnexpectedEOF},
+			},
+			codes:  map[string]int{"a": codeAuthFailed, "b": codeAuthFailed, "c": 0, "d": 0, "e": DispatchCodeBatchFailed},
+			failed: []int{0, 2},
+			progress: FanoutProgress{
+				TotalBatches: 3, DoneBatches: 3, FailedBatches: 2, TotalAgents: 5, DoneAgents: 5, FailedAgents: 3,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var progress FanoutProgress
+
+			fanout, err := NewFanout(&fakeCluster{results: tt.results},
+				WithFanoutBatchSize(2),
+				WithFanoutConcurrency(2),
+				WithFanoutLogger(types.NewEmptyLogger()),
+				WithFanoutProgress(func(p *FanoutProgress) { progress = *p }),
+			)
+			if err != nil {
+				t.Fatalf("new fanout failed: %v", err)
+			}
+
+			resp, err := fanout.PluginDispatchMessage(context.Background(), "message", nil, "a", "b", "c", "d", "e")
+
+			var failed []int
+
+			var fanoutErr *FanoutError
+			if errors.As(err, &fanoutErr) {
+				for _, batch := range fanoutErr.Batches {
+					failed = append(failed, batch.Index)
+				}
+			} else if err != nil {
+				t.Fatalf("dispatch returns %v, want *FanoutError", err)
+			}
+
+			if !reflect.DeepEqual(failed, tt.failed) {
+				t.Fatalf("failed batches %v, want %v", failed, tt.failed)
+			}
+
+			if resp.Code != CodeSuccess {
+				t.Fatalf("merged response code is %d, want %d", resp.Code, CodeSuccess)
+			}
+
+			if codes := resultCodes(resp); !reflect.DeepEqual(codes, tt.codes) {
+				t.Fatalf("merged codes %v, want %v", codes, tt.codes)
+			}
+
+			if len(resp.FailedAgents()) != tt.progress.FailedAgents {
+				t.Fatalf("%d failed agents in response, want %d", len(resp.FailedAgents()), tt.progress.FailedAgents)
+			}
+
+			progress.Batch, tt.progress.MessageID = nil, "message"
+			if progress != tt.progress {
+				t.Fatalf("last progress is %+v, want %+v", progress, tt.progress)
+			}
+		})
+	}
+}
+
+func TestFanoutContextDone(t *testing.T) {
+	fanout, err := NewFanout(&fakeCluster{}, WithFanoutBatchSize(1), WithFanoutLogger(types.NewEmptyLogger()))
+	if err != nil {
+		t.Fatalf("new fanout failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resp, err := fanout.PluginDispatchMessage(ctx, "message", nil, "a", "b")
+	if !errors.Is(err, types.ErrContextDone()) {
+		t.Fatalf("dispatch returns %v, want %v", err, types.ErrContextDone())
+	}
+
+	if len(resp.AgentResults) != 2 {
+		t.Fatalf("%d agents in response, want 2", len(resp.AgentResults))
+	}
+
+	for agentID, result := range resp.AgentResults {
+		if result.Code != DispatchCodeBatchFailed || !strings.Contains(result.Message, "context") {
+			t.Fatalf("agent %s result is %+v, want batch failed", agentID, result)
+		}
+	}
+}
+
+func TestFanoutProgressOutOfLock(t *testing.T) {
+	cluster := &fakeCluster{}
+
+	dispatched := func() int {
+		cluster.mutex.Lock()
+		defer cluster.mutex.Unlock()
+
+		return len(cluster.batches)
+	}
+
+	var done []int
+
+	fanout, err := NewFanout(cluster,
+		WithFanoutBatchSize(1),
+		WithFanoutConcurrency(2),
+		WithFanoutLogger(types.NewEmptyLogger()),
+		WithFanoutProgress(func(p *FanoutProgress) {
+			// the first hook waits for the last batch, which is dispatched after another batch is merged.
+			for deadline := time.Now().Add(time.Second); len(done) == 0 && dispatched() < 3; {
+				if time.Now().After(deadline) {
+					t.Error("batches are blocked by the progress hook")
+					break
+				}
+
+				time.Sleep(time.Millisecond)
+			}
+
+			done = append(done, p.DoneBatches)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("new fanout failed: %v", err)
+	}
+
+	if _, err := fanout.PluginDispatchMessage(context.Background(), "message", nil, "a", "b", "c"); err != nil {
+		t.Fatalf("dispatch failed: %v", err)
+	}
+
+	// the hook calls are serialized and in order.
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(done, want) {
+		t.Fatalf("reported done batches %v, want %v", done, want)
+	}
+}
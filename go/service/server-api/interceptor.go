@@ -0,0 +1,107 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/server"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// Call describes a request to server seen by interceptors, the interceptors could change the header and body
+// before calling the next one.
+type Call = server.Call
+
+// Response describes a response from server seen by interceptors, the body is truncated if the status code
+// is not 200.
+type Response = server.Response
+
+// Invoker sends the call, the error is the failure without response, e.g. network error.
+type Invoker = server.Invoker
+
+// Interceptor intercepts every request to server, it should call next to continue.
+// the interceptors are chained in order, the first one is the outermost, and every retry passes through them.
+type Interceptor = server.Interceptor
+
+// LoggingInterceptor logs every request with the status code and latency in debug level,
+// and the failed ones in warn level.
+func LoggingInterceptor(logger types.Logger) Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) (*Response, error) {
+		start := time.Now()
+
+		resp, err := next(ctx, call)
+		latency := time.Since(start)
+
+		switch {
+		case err != nil:
+			logger.Warn("request to server failed. method: %s, path: %s, latency: %s, err: %v",
+				call.Method, call.Path, latency.String(), err)
+
+		case resp.StatusCode >= http.StatusBadRequest:
+			logger.Warn("request to server failed. method: %s, path: %s, status: %d, latency: %s, body: %s",
+				call.Method, call.Path, resp.StatusCode, latency.String(), string(resp.Body))
+
+		default:
+			logger.Debug("request to server. method: %s, path: %s, status: %d, latency: %s, request: %d bytes, "+
+				"response: %d bytes", call.Method, call.Path, resp.StatusCode, latency.String(),
+				len(call.Body), len(resp.Body))
+		}
+
+		return resp, err
+	}
+}
+
+// Timing describes the timing of a request to server.
+type Timing struct {
+	Method string
+	Path   string
+
+	// StatusCode is the http status code, 0 if the request failed without response.
+	StatusCode int
+
+	// Latency is the time spent in the rest of chain, including reading the response body.
+	Latency time.Duration
+
+	// Err is the failure without response.
+	Err error
+}
+
+// TimingInterceptor measures every request and reports it to observe, e.g. to record metrics.
+func TimingInterceptor(observe func(timing *Timing)) Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) (*Response, error) {
+		start := time.Now()
+
+		resp, err := next(ctx, call)
+
+		timing := &Timing{
+			Method:  call.Method,
+			Path:    call.Path,
+			Latency: time.Since(start),
+			Err:     err,
+		}
+
+		if resp != nil {
+			timing.StatusCode = resp.StatusCode
+		}
+
+		observe(timing)
+
+		return resp, err
+	}
+}
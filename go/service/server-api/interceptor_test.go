@@ -0,0 +1,131 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	serverapi "github.com/TencentBlueKing/bk-gse-sdk/go/service/server-api"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/service/server-api/serverapitest"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// newInterceptedCluster creates a cluster client of the fake server with the interceptors.
+func newInterceptedCluster(t *testing.T, interceptors ...serverapi.Interceptor) (*serverapitest.Server,
+	serverapi.Cluster) {
+
+	t.Helper()
+
+	fake := serverapitest.NewServer(serverapitest.WithClusterAuth(collectSlotID, collectToken))
+	t.Cleanup(fake.Close)
+
+	client, err := serverapi.New(
+		serverapi.WithBaseURL(fake.URL),
+		serverapi.WithClusterAuth(collectSlotID, collectToken),
+		serverapi.WithInterceptors(interceptors...),
+		serverapi.WithRetryPolicy(serverapi.RetryPolicy{
+			MaxAttempts:       2,
+			InitialBackoff:    time.Millisecond,
+			MaxBackoff:        time.Millisecond,
+			Multiplier:        1,
+			RetryOnHTTPStatus: []int{http.StatusServiceUnavailable},
+		}),
+		serverapi.WithLogger(types.NewEmptyLogger()),
+	)
+	if err != nil {
+		t.Fatalf("new client failed: %v", err)
+	}
+
+	return fake, client.Cluster()
+}
+
+// recordInterceptor records the calls passing through it in name.
+func recordInterceptor(name string, calls *[]string) serverapi.Interceptor {
+	return func(ctx context.Context, call *serverapi.Call, next serverapi.Invoker) (*serverapi.Response, error) {
+		*calls = append(*calls, name+" before")
+
+		resp, err := next(ctx, call)
+		*calls = append(*calls, name+" after")
+
+		return resp, err
+	}
+}
+
+func TestInterceptorOrder(t *testing.T) {
+	var calls []string
+
+	header := func(ctx context.Context, call *serverapi.Call, next serverapi.Invoker) (*serverapi.Response, error) {
+		call.Header.Set("X-Trace-Id", "trace")
+		return next(ctx, call)
+	}
+
+	fake, cluster := newInterceptedCluster(t,
+		recordInterceptor("outer", &calls), header, recordInterceptor("inner", &calls))
+
+	// every retry passes through the whole chain.
+	fake.ScriptResponses(serverapitest.Response{StatusCode: http.StatusServiceUnavailable})
+
+	if _, err := cluster.PluginDispatchMessage(context.Background(), "message", []byte("ping"), "a"); err != nil {
+		t.Fatalf("dispatch message failed: %v", err)
+	}
+
+	attempt := []string{"outer before", "inner before", "inner after", "outer after"}
+	if want := append(attempt, attempt...); !reflect.DeepEqual(calls, want) {
+		t.Fatalf("intercepted calls %v, want %v", calls, want)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("%d requests recorded, want 2", len(requests))
+	}
+
+	for i, request := range requests {
+		if traceID := request.Header.Get("X-Trace-Id"); traceID != "trace" {
+			t.Fatalf("request %d is sent with trace id %q, want the one set by interceptor", i, traceID)
+		}
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	var calls []string
+
+	errRejected := errors.New("rejected")
+	reject := func(context.Context, *serverapi.Call, serverapi.Invoker) (*serverapi.Response, error) {
+		return nil, errRejected
+	}
+
+	fake, cluster := newInterceptedCluster(t,
+		recordInterceptor("outer", &calls), reject, recordInterceptor("inner", &calls))
+
+	_, err := cluster.PluginDispatchMessage(context.Background(), "message", []byte("ping"), "a")
+	if !errors.Is(err, errRejected) {
+		t.Fatalf("dispatch message returns %v, want %v", err, errRejected)
+	}
+
+	// the interceptors after the short-circuit one and the server are never called.
+	if want := []string{"outer before", "outer after"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("intercepted calls %v, want %v", calls, want)
+	}
+
+	if requests := fake.Requests(); len(requests) != 0 {
+		t.Fatalf("%d requests recorded, want 0", len(requests))
+	}
+}
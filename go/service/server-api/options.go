@@ -17,6 +17,7 @@ package serverapi
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/TencentBlueKing/bk-gse-sdk/go/internal"
 	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
@@ -46,6 +47,13 @@ func WithClient(client *http.Client) OptionFn {
 	}
 }
 
+// WithRequestTimeout sets the timeout of every request to server, 0 means no timeout.
+func WithRequestTimeout(timeout time.Duration) OptionFn {
+	return func(c *Config) {
+		c.RequestTimeout = timeout
+	}
+}
+
 // WithClusterAuth sets the cluster auth.
 func WithClusterAuth(slotID int, token string) OptionFn {
 	return func(c *Config) {
@@ -62,6 +70,78 @@ func WithAPIGwAuth(appCode, appSecret string) OptionFn {
 	}
 }
 
+// WithChunkedTransfer enables the chunked transfer for the messages larger than chunkSizeBytes,
+// and drops the partial received chunked messages after timeout.
+func WithChunkedTransfer(chunkSizeBytes uint32, timeout time.Duration) OptionFn {
+	return func(c *Config) {
+		c.ChunkSizeBytes = chunkSizeBytes
+		c.ChunkTimeout = timeout
+	}
+}
+
+// WithMaxChunkedMessageBytes sets the max size in bytes of a reassembled chunked message.
+func WithMaxChunkedMessageBytes(size uint64) OptionFn {
+	return func(c *Config) {
+		c.MaxChunkedMessageBytes = size
+	}
+}
+
+// WithMaxPendingChunked sets the max number and total received bytes of the partial chunked messages,
+// the oldest ones are dropped when over the limits.
+func WithMaxPendingChunked(count int, size uint64) OptionFn {
+	return func(c *Config) {
+		c.MaxPendingChunked = count
+		c.MaxPendingChunkedBytes = size
+	}
+}
+
+// WithCodec sets the codec of typed message content.
+func WithCodec(codec types.Codec) OptionFn {
+	return func(c *Config) {
+		c.Codec = codec
+	}
+}
+
+// WithEnvelope enables signing and optionally encryption of the message content shared with plugins.
+func WithEnvelope(envelope types.EnvelopeConfig) OptionFn {
+	return func(c *Config) {
+		c.Envelope = envelope
+	}
+}
+
+// WithRetryPolicy sets the retry policy of dispatching, see NewDefaultRetryPolicy for the defaults.
+func WithRetryPolicy(policy RetryPolicy) OptionFn {
+	return func(c *Config) {
+		c.RetryPolicy = &policy
+	}
+}
+
+// WithErrorCode registers the classification of the GSE code, e.g. the code of plugin not connected
+// to types.ErrPluginNotConnected() and retryable.
+func WithErrorCode(code int, class ErrorCode) OptionFn {
+	return func(c *Config) {
+		if c.ErrorCodes == nil {
+			c.ErrorCodes = make(map[int]ErrorCode)
+		}
+
+		c.ErrorCodes[code] = class
+	}
+}
+
+// WithAgentBatchSize sets the max number of agents in every agent state or info query.
+func WithAgentBatchSize(size int) OptionFn {
+	return func(c *Config) {
+		c.AgentBatchSize = size
+	}
+}
+
+// WithInterceptors appends the interceptors to the chain of every request to server.
+func WithInterceptors(interceptors ...Interceptor) OptionFn {
+	return func(c *Config) {
+		c.Interceptors = append(c.Interceptors, interceptors...)
+	}
+}
+
 // WithLogger sets the logger to use for requests.
 func WithLogger(logger types.Logger) OptionFn {
 	return func(c *Config) {
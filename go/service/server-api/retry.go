@@ -0,0 +1,254 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// NewDefaultRetryPolicy creates a default retry policy, which retries the network errors,
+// the http status 429, 500, 502, 503, 504 and the retryable GSE codes up to 3 attempts.
+func NewDefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:         defaultRetryMaxAttempts,
+		InitialBackoff:      defaultRetryInitialBackoff,
+		MaxBackoff:          defaultRetryMaxBackoff,
+		Multiplier:          defaultRetryMultiplier,
+		Jitter:              defaultRetryJitter,
+		RetryOnNetworkError: true,
+		RetryOnRetryable:    true,
+		RetryOnHTTPStatus: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 200 * time.Millisecond
+	defaultRetryMaxBackoff     = 5 * time.Second
+	defaultRetryMultiplier     = 2
+	defaultRetryJitter         = 0.2
+)
+
+// RetryPolicy describes how to retry the failed dispatching.
+// every attempt reuses the same message id, so that GSE could dedupe the message.
+type RetryPolicy struct {
+	// MaxAttempts is the max attempts of a dispatching including the first one, 1 means no retry.
+	MaxAttempts int
+
+	// InitialBackoff is the wait time before the first retry, then multiplied by Multiplier on every retry
+	// until MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+
+	// Jitter randomizes the backoff in range [backoff*(1-Jitter), backoff*(1+Jitter)], in range [0, 1].
+	Jitter float64
+
+	// RetryOnNetworkError retries the whole request on network errors.
+	RetryOnNetworkError bool
+
+	// RetryOnHTTPStatus retries the whole request on the http status codes.
+	RetryOnHTTPStatus []int
+
+	// RetryOnCodes retries the whole request on the GSE response codes.
+	RetryOnCodes []int
+
+	// RetryOnAgentCodes retries only the agents whose result codes indicate a transient failure.
+	RetryOnAgentCodes []int
+
+	// RetryOnRetryable retries the GSE codes classified as retryable in Config.ErrorCodes, of both the whole
+	// request and agents, in addition to RetryOnCodes and RetryOnAgentCodes.
+	RetryOnRetryable bool
+}
+
+// Validate validates the retry policy.
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("retry max attempts is less than 1"))
+	}
+
+	if p.InitialBackoff < 0 || p.MaxBackoff < p.InitialBackoff {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("invalid retry backoff"))
+	}
+
+	if p.Multiplier < 1 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("retry multiplier is less than 1"))
+	}
+
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return errors.Join(types.ErrInvalidConfig(), errors.New("retry jitter is out of range [0, 1]"))
+	}
+
+	return nil
+}
+
+// retryableError returns true if the request failed in err should be retried.
+func (p RetryPolicy) retryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr *types.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return slices.Contains(p.RetryOnHTTPStatus, statusErr.StatusCode)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return p.RetryOnNetworkError
+	}
+
+	return false
+}
+
+// retryableCode returns true if the whole request failed in code should be retried.
+func (p RetryPolicy) retryableCode(codes map[int]ErrorCode, code int) bool {
+	return slices.Contains(p.RetryOnCodes, code) || (p.RetryOnRetryable && codes[code].Retryable)
+}
+
+// transientAgents returns the agents whose failures in result should be retried.
+func (p RetryPolicy) transientAgents(codes map[int]ErrorCode, result *ClusterPluginDispatchMessageResp) []string {
+	agents := make([]string, 0)
+	for agentID, agentResult := range result.AgentResults {
+		if slices.Contains(p.RetryOnAgentCodes, agentResult.Code) ||
+			(p.RetryOnRetryable && codes[agentResult.Code].Retryable) {
+			agents = append(agents, agentID)
+		}
+	}
+
+	// keep the order of agents stable in every retry.
+	slices.Sort(agents)
+
+	return agents
+}
+
+// backoff returns the wait time before the retry after attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	backoff = min(backoff, float64(p.MaxBackoff))
+
+	if p.Jitter > 0 {
+		backoff *= 1 + p.Jitter*(2*rand.Float64()-1) // nolint:gosec
+	}
+
+	return time.Duration(backoff)
+}
+
+// dispatchFunc dispatches the message to the target agents.
+type dispatchFunc func(targets []string) (*ClusterPluginDispatchMessageResp, error)
+
+// dispatchWithRetry calls dispatch with the retry policy. the whole request is retried on the retryable errors
+// and codes, and only the agents with transient failures are retried after a successful request.
+// when the retries run out, the agents keep their last failures in the result.
+func (c *clusterClient) dispatchWithRetry(ctx context.Context, messageID string, targets []string,
+	dispatch dispatchFunc) (*ClusterPluginDispatchMessageResp, error) {
+
+	if c.conf.RetryPolicy == nil {
+		return dispatch(targets)
+	}
+
+	policy := c.conf.RetryPolicy
+
+	var result *ClusterPluginDispatchMessageResp
+
+	for attempt := 1; ; attempt++ {
+		resp, err := dispatch(targets)
+
+		var retry bool
+		var reason string
+
+		switch {
+		case err != nil:
+			retry, reason = policy.retryableError(ctx, err), err.Error()
+
+		case resp.Code != 0:
+			retry, reason = policy.retryableCode(c.conf.ErrorCodes, resp.Code), fmt.Sprintf("code %d", resp.Code)
+
+		default:
+			result = mergeRetriedResp(result, targets, resp)
+			targets = policy.transientAgents(c.conf.ErrorCodes, result)
+			retry, reason = len(targets) != 0, fmt.Sprintf("%d agents failed", len(targets))
+		}
+
+		if !retry || attempt >= policy.MaxAttempts {
+			if result != nil {
+				return result, nil
+			}
+
+			return resp, err
+		}
+
+		if !wait(ctx, policy.backoff(attempt)) {
+			if result != nil {
+				return result, nil
+			}
+
+			return nil, errors.Join(types.ErrContextDone(), ctx.Err(), err)
+		}
+
+		c.conf.Logger.Debug("retry dispatching message. message-id: %s, attempt: %d, reason: %s",
+			messageID, attempt+1, reason)
+	}
+}
+
+// mergeRetriedResp merges the response of the retried targets into result.
+func mergeRetriedResp(result *ClusterPluginDispatchMessageResp, targets []string,
+	resp *ClusterPluginDispatchMessageResp) *ClusterPluginDispatchMessageResp {
+
+	if result == nil {
+		return resp
+	}
+
+	for _, agentID := range targets {
+		delete(result.AgentResults, agentID)
+	}
+
+	for agentID, agentResult := range resp.AgentResults {
+		result.AgentResults[agentID] = agentResult
+	}
+
+	return result
+}
+
+// wait waits for the duration, returns false if the context is done.
+func wait(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,342 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// the failure codes in tests, they are registered as callers do.
+const (
+	codeAuthFailed         = 1000401
+	codeAgentOffline       = 1001001
+	codePluginNotConnected = 1001002
+)
+
+func testErrorCodes() map[int]ErrorCode {
+	return map[int]ErrorCode{
+		codeAuthFailed:         {Err: types.ErrNotAthorized()},
+		codeAgentOffline:       {Err: types.ErrAgentOffline()},
+		codePluginNotConnected: {Err: types.ErrPluginNotConnected(), Retryable: true},
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     3,
+	}
+
+	tests := []struct {
+		attempt int
+		backoff time.Duration
+	}{
+		{attempt: 1, backoff: 100 * time.Millisecond},
+		{attempt: 2, backoff: 300 * time.Millisecond},
+		{attempt: 3, backoff: 900 * time.Millisecond},
+		{attempt: 4, backoff: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			if backoff := policy.backoff(tt.attempt); backoff != tt.backoff {
+				t.Fatalf("backoff returns %v, want %v", backoff, tt.backoff)
+			}
+		})
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if backoff := policy.backoff(1); backoff < 50*time.Millisecond || backoff > 150*time.Millisecond {
+			t.Fatalf("backoff returns %v with jitter, want in [50ms, 150ms]", backoff)
+		}
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *RetryPolicy)
+		valid  bool
+	}{
+		{name: "default", modify: func(*RetryPolicy) {}, valid: true},
+		{name: "no attempt", modify: func(p *RetryPolicy) { p.MaxAttempts = 0 }},
+		{name: "negative backoff", modify: func(p *RetryPolicy) { p.InitialBackoff = -1 }},
+		{name: "max under initial", modify: func(p *RetryPolicy) { p.MaxBackoff = p.InitialBackoff - 1 }},
+		{name: "multiplier under 1", modify: func(p *RetryPolicy) { p.Multiplier = 0.5 }},
+		{name: "jitter over 1", modify: func(p *RetryPolicy) { p.Jitter = 1.5 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := NewDefaultRetryPolicy()
+			tt.modify(&policy)
+
+			if err := policy.Validate(); (err == nil) != tt.valid {
+				t.Fatalf("validate returns %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyTransientAgents(t *testing.T) {
+	policy := RetryPolicy{RetryOnAgentCodes: []int{100}}
+	result := &ClusterPluginDispatchMessageResp{
+		AgentResults: map[string]*types.DispatchAgentResult{
+			"a": {Code: CodeSuccess},
+			"b": {Code: codePluginNotConnected},
+			"c": {Code: 100},
+			"d": {Code: codeAgentOffline},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		retryable bool
+		agents    []string
+	}{
+		{name: "agent codes", agents: []string{"c"}},
+		{name: "retryable codes", retryable: true, agents: []string{"b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy.RetryOnRetryable = tt.retryable
+
+			if agents := policy.transientAgents(testErrorCodes(), result); !reflect.DeepEqual(agents, tt.agents) {
+				t.Fatalf("transient agents returns %v, want %v", agents, tt.agents)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	codes := testErrorCodes()
+
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "nil", err: nil},
+		{name: "context done", err: errors.Join(types.ErrContextDone(), io.ErrUnexpectedEOF)},
+		{name: "retryable code", err: newDispatchError(codes, "", codePluginNotConnected, ""), retryable: true},
+		{name: "unretryable code", err: newDispatchError(codes, "", codeAgentOffline, "")},
+		{name: "unregistered code", err: newDispatchError(nil, "", codePluginNotConnected, "")},
+		{name: "http 429", err: &types.HTTPStatusError{StatusCode: http.StatusTooManyRequests}, retryable: true},
+		{name: "http 502", err: &types.HTTPStatusError{StatusCode: http.StatusBadGateway}, retryable: true},
+		{name: "http 400", err: &types.HTTPStatusError{StatusCode: http.StatusBadRequest}},
+		{name: "unexpected eof", err: fmt.Errorf("read: %w", io.ErrUnexpectedEOF), retryable: true},
+		{name: "other", err: errors.New("other")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if retryable := IsRetryable(tt.err); retryable != tt.retryable {
+				t.Fatalf("is retryable returns %v, want %v", retryable, tt.retryable)
+			}
+		})
+	}
+}
+
+// dispatchResult is a scripted result of a dispatch attempt.
+type dispatchResult struct {
+	resp *ClusterPluginDispatchMessageResp
+	err  error
+}
+
+// agentResults creates the agent results from agent id to code.
+func agentResults(codes map[string]int) map[string]*types.DispatchAgentResult {
+	results := make(map[string]*types.DispatchAgentResult, len(codes))
+	for agentID, code := range codes {
+		results[agentID] = &types.DispatchAgentResult{AgentID: agentID, Code: code}
+	}
+
+	return results
+}
+
+// resultCodes returns the codes of agent results by agent id.
+func resultCodes(resp *ClusterPluginDispatchMessageResp) map[string]int {
+	if resp == nil {
+		return nil
+	}
+
+	codes := make(map[string]int, len(resp.AgentResults))
+	for agentID, result := range resp.AgentResults {
+		codes[agentID] = result.Code
+	}
+
+	return codes
+}
+
+func TestDispatchWithRetry(t *testing.T) {
+	networkErr := fmt.Errorf("read: %w", io.ErrUnexpectedEOF)
+	badRequest := &types.HTTPStatusError{StatusCode: http.StatusBadRequest}
+
+	tests := []struct {
+		name    string
+		results []dispatchResult
+		targets [][]string
+		codes   map[string]int
+		err     error
+	}{
+		{
+			name: "success",
+			results: []dispatchResult{
+				{resp: &ClusterPluginDispatchMessageResp{AgentResults: agentResults(map[string]int{"a": 0, "b": 0})}},
+			},
+			targets: [][]string{{"a", "b"}},
+			codes:   map[string]int{"a": 0, "b": 0},
+		},
+		{
+			name: "retry network error",
+			results: []dispatchResult{
+				{err: networkErr},
+				{resp: &ClusterPluginDispatchMessageResp{AgentResults: agentResults(map[string]int{"a": 0, "b": 0})}},
+			},
+			targets: [][]string{{"a", "b"}, {"a", "b"}},
+			codes:   map[string]int{"a": 0, "b": 0},
+		},
+		{
+			name: "unretryable error",
+			results: []dispatchResult{
+				{err: badRequest},
+			},
+			targets: [][]string{{"a", "b"}},
+			err:     badRequest,
+		},
+		{
+			name: "retry code",
+			results: []dispatchResult{
+				{resp: &ClusterPluginDispatchMessageResp{Code: codePluginNotConnected}},
+				{resp: &ClusterPluginDispatchMessageResp{AgentResults: agentResults(map[string]int{"a": 0, "b": 0})}},
+			},
+			targets: [][]string{{"a", "b"}, {"a", "b"}},
+			codes:   map[string]int{"a": 0, "b": 0},
+		},
+		{
+			name: "retry transient agents",
+			results: []dispatchResult{
+				{resp: &ClusterPluginDispatchMessageResp{AgentResults: agentResults(map[string]int{
+					"a": 0, "b": codePluginNotConnected, "c": codeAgentOffline,
+				})}},
+				{resp: &ClusterPluginDispatchMessageResp{AgentResults: agentResults(map[string]int{"b": 0})}},
+			},
+			targets: [][]string{{"a", "b", "c"}, {"b"}},
+			codes:   map[string]int{"a": 0, "b": 0, "c": codeAgentOffline},
+		},
+		{
+			name: "keep last failures",
+			results: []dispatchResult{
+				{resp: &ClusterPluginDispatchMessageResp{AgentResults: agentResults(map[string]int{
+					"a": 0, "b": codePluginNotConnected,
+				})}},
+				{resp: &ClusterPluginDispatchMessageResp{AgentResults: agentResults(map[string]int{
+					"b": codePluginNotConnected,
+				})}},
+				{resp: &ClusterPluginDispatchMessageResp{AgentResults: agentResults(map[string]int{
+					"b": codePluginNotConnected,
+				})}},
+			},
+			targets: [][]string{{"a", "b"}, {"b"}, {"b"}},
+			codes:   map[string]int{"a": 0, "b": codePluginNotConnected},
+		},
+		{
+			name: "keep result on failed retry",
+			results: []dispatchResult{
+				{resp: &ClusterPluginDispatchMessageResp{AgentResults: agentResults(map[string]int{
+					"a": 0, "b": codePluginNotConnected,
+				})}},
+				{err: badRequest},
+			},
+			targets: [][]string{{"a", "b"}, {"b"}},
+			codes:   map[string]int{"a": 0, "b": codePluginNotConnected},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := NewDefaultRetryPolicy()
+			policy.InitialBackoff, policy.Jitter = 0, 0
+
+			c := &clusterClient{client: &client{conf: &Config{
+				RetryPolicy: &policy,
+				ErrorCodes:  testErrorCodes(),
+				Logger:      types.NewEmptyLogger(),
+			}}}
+
+			var targets [][]string
+			resp, err := c.dispatchWithRetry(context.Background(), "message", slices.Clone(tt.targets[0]),
+				func(targetAgents []string) (*ClusterPluginDispatchMessageResp, error) {
+					targets = append(targets, targetAgents)
+					result := tt.results[len(targets)-1]
+
+					return result.resp, result.err
+				})
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("dispatch returns %v, want %v", err, tt.err)
+			}
+
+			if !reflect.DeepEqual(targets, tt.targets) {
+				t.Fatalf("dispatched targets %v, want %v", targets, tt.targets)
+			}
+
+			if codes := resultCodes(resp); !reflect.DeepEqual(codes, tt.codes) {
+				t.Fatalf("dispatch returns codes %v, want %v", codes, tt.codes)
+			}
+		})
+	}
+}
+
+func TestDispatchWithRetryContextDone(t *testing.T) {
+	policy := NewDefaultRetryPolicy()
+	policy.InitialBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+
+	c := &clusterClient{client: &client{conf: &Config{
+		RetryPolicy: &policy,
+		ErrorCodes:  testErrorCodes(),
+		Logger:      types.NewEmptyLogger(),
+	}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	_, err := c.dispatchWithRetry(ctx, "message", []string{"a"},
+		func([]string) (*ClusterPluginDispatchMessageResp, error) {
+			attempts++
+
+			return &ClusterPluginDispatchMessageResp{Code: codePluginNotConnected}, nil
+		})
+
+	if !errors.Is(err, types.ErrContextDone()) || attempts != 1 {
+		t.Fatalf("dispatch returns %v after %d attempts, want %v", err, attempts, types.ErrContextDone())
+	}
+}
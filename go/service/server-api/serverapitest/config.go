@@ -0,0 +1,80 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapitest
+
+// NewDefaultConfig creates a default configuration for fake server.
+func NewDefaultConfig() *Config {
+	return &Config{
+		SlotID:      0,
+		Token:       "",
+		AppCode:     "",
+		AppSecret:   "",
+		CallbackURL: "",
+		Reply:       nil,
+	}
+}
+
+// Config defines the configuration for fake server.
+type Config struct {
+	// SlotID, Token describes the cluster auth to validate, empty token means no validation.
+	SlotID int
+	Token  string
+
+	// AppCode, AppSecret describes the api gateway auth to validate, empty app code means no validation.
+	AppCode   string
+	AppSecret string
+
+	// CallbackURL describes the url to fire respond message callbacks.
+	CallbackURL string
+
+	// Reply describes how the plugins reply the dispatched messages, it's optional.
+	// it's called for every agent succeeded in dispatching, and the content is replied with the same message id
+	// through callback if ok is true.
+	Reply func(messageID, agentID, content string) (reply []byte, ok bool)
+}
+
+// OptionFn defines the function type for setting options.
+type OptionFn func(*Config)
+
+// WithClusterAuth sets the cluster auth to validate.
+func WithClusterAuth(slotID int, token string) OptionFn {
+	return func(c *Config) {
+		c.SlotID = slotID
+		c.Token = token
+	}
+}
+
+// WithAPIGwAuth sets the api gateway auth to validate.
+func WithAPIGwAuth(appCode, appSecret string) OptionFn {
+	return func(c *Config) {
+		c.AppCode = appCode
+		c.AppSecret = appSecret
+	}
+}
+
+// WithCallbackURL sets the url to fire respond message callbacks.
+func WithCallbackURL(url string) OptionFn {
+	return func(c *Config) {
+		c.CallbackURL = url
+	}
+}
+
+// WithReply sets how the plugins reply the dispatched messages.
+func WithReply(reply func(messageID, agentID, content string) (reply []byte, ok bool)) OptionFn {
+	return func(c *Config) {
+		c.Reply = reply
+	}
+}
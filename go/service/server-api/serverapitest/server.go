@@ -0,0 +1,503 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+// Package serverapitest provides a fake GSE cluster server for testing the server side code with serverapi.
+package serverapitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/internal/server"
+	serverapi "github.com/TencentBlueKing/bk-gse-sdk/go/service/server-api"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+const (
+	pathDispatchMessage      = "/api/v2/cluster/dispatch_message"
+	pathDispatchMultiMessage = "/api/v2/cluster/dispatch_multi_message"
+	pathListAgentState       = "/api/v2/cluster/list_agent_state"
+	pathListAgentInfo        = "/api/v2/cluster/list_agent_info"
+
+	headerAPIGwAuthKey = "X-Bkapi-Authorization"
+)
+
+// the codes the fake server answers the cluster auth failures with, they are made up for tests rather than
+// taken from GSE, register them by serverapi.WithErrorCode to classify the failures.
+const (
+	CodeAuthFailed   = 1000401
+	CodeSlotNotFound = 1000404
+)
+
+// Request describes a request received by fake server.
+type Request struct {
+	// Path is the api path of request.
+	Path string
+
+	// Header is the http header of request.
+	Header http.Header
+
+	SlotID    int
+	Token     string
+	MessageID string
+
+	// AgentIDList is the target agents in order.
+	AgentIDList []string
+
+	// Contents is the content received by every agent, the front and back content of multi message are joined.
+	Contents map[string]string
+}
+
+// Response describes a scripted response of the whole request.
+type Response struct {
+	// StatusCode is the http status code, 0 means 200.
+	StatusCode int
+
+	// Code and Message are the GSE code and message in body.
+	Code    int
+	Message string
+}
+
+// AgentResult describes a scripted dispatch result of an agent.
+type AgentResult struct {
+	Code    int
+	Message string
+}
+
+// Server is a fake GSE cluster server built on httptest.Server, which implements the dispatch apis.
+type Server struct {
+	// Server is the underlying http server, use URL as the base url of serverapi.
+	*httptest.Server
+
+	conf *Config
+
+	requests     []*Request
+	responses    []Response
+	agentResults map[string][]AgentResult
+	agents       map[string]types.AgentInfo
+	replyErrors  []error
+	mutex        sync.Mutex
+
+	// replies counts the replies in flight, closed stops firing new ones, both are protected by mutex.
+	replies sync.WaitGroup
+	closed  bool
+}
+
+// NewServer creates and starts a new fake server, it should be closed after use.
+func NewServer(opts ...OptionFn) *Server {
+	conf := NewDefaultConfig()
+
+	for _, opt := range opts {
+		opt(conf)
+	}
+
+	s := &Server{
+		conf:         conf,
+		agentResults: make(map[string][]AgentResult),
+		agents:       make(map[string]types.AgentInfo),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathDispatchMessage, s.handleDispatchMessage)
+	mux.HandleFunc(pathDispatchMultiMessage, s.handleDispatchMultiMessage)
+	mux.HandleFunc(pathListAgentState, s.handleListAgent)
+	mux.HandleFunc(pathListAgentInfo, s.handleListAgent)
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Close waits for the replies in flight, and shuts down the server.
+func (s *Server) Close() {
+	s.mutex.Lock()
+	s.closed = true
+	s.mutex.Unlock()
+
+	s.replies.Wait()
+	s.Server.Close()
+}
+
+// SetCallbackURL sets the url to fire respond message callbacks.
+func (s *Server) SetCallbackURL(url string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.conf.CallbackURL = url
+}
+
+// ScriptResponses scripts the responses of the next requests in order, the requests after them succeed.
+func (s *Server) ScriptResponses(responses ...Response) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.responses = append(s.responses, responses...)
+}
+
+// ScriptAgent scripts the results of the agent in the next requests to it in order,
+// the requests after them succeed.
+func (s *Server) ScriptAgent(agentID string, results ...AgentResult) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.agentResults[agentID] = append(s.agentResults[agentID], results...)
+}
+
+// SetAgents sets the agents known to the server, the agents not set are unknown in agent state or info queries.
+func (s *Server) SetAgents(agents ...types.AgentInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, agent := range agents {
+		s.agents[agent.AgentID] = agent
+	}
+}
+
+// Requests returns the received requests in order, including the failed ones.
+func (s *Server) Requests() []*Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]*Request(nil), s.requests...)
+}
+
+// ReplyErrors returns the failures of firing callbacks by Reply.
+func (s *Server) ReplyErrors() []error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]error(nil), s.replyErrors...)
+}
+
+// Reset clears the recorded requests, reply errors and the scripts, the agents are kept.
+func (s *Server) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests = nil
+	s.responses = nil
+	s.agentResults = make(map[string][]AgentResult)
+	s.replyErrors = nil
+}
+
+// Respond fires a respond message callback to the callback url, as the plugin on agent sent the content.
+func (s *Server) Respond(ctx context.Context, messageID, agentID string, content []byte) error {
+	s.mutex.Lock()
+	callbackURL := s.conf.CallbackURL
+	s.mutex.Unlock()
+
+	if callbackURL == "" {
+		return errors.New("callback url is empty")
+	}
+
+	body, err := json.Marshal(&server.ClusterRespondMessage{
+		MessageID: messageID,
+		AgentID:   agentID,
+		Content:   string(content),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("callback of message %s from agent %s failed, status code: %d",
+			messageID, agentID, resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (s *Server) handleDispatchMessage(w http.ResponseWriter, r *http.Request) {
+	body := new(server.ClusterDispatchMessageReq)
+
+	s.handle(w, r, body, func() *Request {
+		request := &Request{
+			SlotID:      body.SlotID,
+			Token:       body.Token,
+			MessageID:   body.MessageID,
+			AgentIDList: body.AgentIDList,
+			Contents:    make(map[string]string, len(body.AgentIDList)),
+		}
+
+		for _, agentID := range body.AgentIDList {
+			request.Contents[agentID] = body.Content
+		}
+
+		return request
+	})
+}
+
+func (s *Server) handleDispatchMultiMessage(w http.ResponseWriter, r *http.Request) {
+	body := new(server.ClusterDispatchMultiMessageReq)
+
+	s.handle(w, r, body, func() *Request {
+		request := &Request{
+			SlotID:      body.SlotID,
+			Token:       body.Token,
+			MessageID:   body.MessageID,
+			AgentIDList: make([]string, 0, len(body.AgentMessageList)),
+			Contents:    make(map[string]string, len(body.AgentMessageList)),
+		}
+
+		for _, message := range body.AgentMessageList {
+			request.AgentIDList = append(request.AgentIDList, message.AgentID)
+			request.Contents[message.AgentID] = body.FrontContent + message.Content + body.BackContent
+		}
+
+		return request
+	})
+}
+
+func (s *Server) handleListAgent(w http.ResponseWriter, r *http.Request) {
+	body := new(server.ListAgentReq)
+
+	s.handle(w, r, body, func() *Request {
+		return &Request{AgentIDList: body.AgentIDList, Contents: make(map[string]string)}
+	})
+}
+
+// handle decodes the body, records the request and writes the scripted response.
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, body any, newRequest func() *Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	request := newRequest()
+	request.Path = r.URL.Path
+	request.Header = r.Header.Clone()
+
+	s.mutex.Lock()
+	s.requests = append(s.requests, request)
+	statusCode, resp := s.respond(request)
+
+	var listResp any
+	if statusCode == http.StatusOK && isListAgent(request.Path) {
+		listResp = s.listAgent(request, resp)
+	}
+	s.mutex.Unlock()
+
+	if statusCode != http.StatusOK {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if listResp != nil {
+		_ = json.NewEncoder(w).Encode(listResp)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(resp)
+
+	if resp.Code == serverapi.CodeSuccess {
+		s.reply(request, resp)
+	}
+}
+
+// listAgent generates the response of agent state or info query, it's called with lock.
+func (s *Server) listAgent(request *Request, resp *server.ClusterDispatchMessageResp) any {
+	agents := make([]types.AgentInfo, 0, len(request.AgentIDList))
+	if resp.Code == serverapi.CodeSuccess {
+		for _, agentID := range request.AgentIDList {
+			if agent, ok := s.agents[agentID]; ok {
+				agents = append(agents, agent)
+			}
+		}
+	}
+
+	if request.Path == pathListAgentState {
+		result := &server.ListAgentStateResp{Code: resp.Code, Message: resp.Message,
+			Data: make([]*server.AgentStateItem, 0, len(agents))}
+
+		for _, agent := range agents {
+			result.Data = append(result.Data, &server.AgentStateItem{
+				AgentID:    agent.AgentID,
+				CloudID:    agent.CloudID,
+				StatusCode: int(agent.StatusCode),
+			})
+		}
+
+		return result
+	}
+
+	result := &server.ListAgentInfoResp{Code: resp.Code, Message: resp.Message,
+		Data: make([]*server.AgentInfoItem, 0, len(agents))}
+
+	for _, agent := range agents {
+		result.Data = append(result.Data, &server.AgentInfoItem{
+			AgentID:    agent.AgentID,
+			CloudID:    agent.CloudID,
+			Version:    agent.Version,
+			RunMode:    agent.RunMode,
+			StatusCode: int(agent.StatusCode),
+		})
+	}
+
+	return result
+}
+
+// respond validates the auth and generates the response by scripts, it's called with lock.
+func (s *Server) respond(request *Request) (int, *server.ClusterDispatchMessageResp) {
+	resp := new(server.ClusterDispatchMessageResp)
+
+	if !s.authorizedAPIGw(request.Header) {
+		return http.StatusUnauthorized, nil
+	}
+
+	// the agent queries are authorized by api gateway only.
+	checkCluster := s.conf.Token != "" && !isListAgent(request.Path)
+
+	if checkCluster && request.SlotID != s.conf.SlotID {
+		resp.Code, resp.Message = CodeSlotNotFound, fmt.Sprintf("slot %d not found", request.SlotID)
+		return http.StatusOK, resp
+	}
+
+	if checkCluster && request.Token != s.conf.Token {
+		resp.Code, resp.Message = CodeAuthFailed, "invalid token"
+		return http.StatusOK, resp
+	}
+
+	if len(s.responses) != 0 {
+		scripted := s.responses[0]
+		s.responses = s.responses[1:]
+
+		if scripted.StatusCode != 0 && scripted.StatusCode != http.StatusOK {
+			return scripted.StatusCode, nil
+		}
+
+		if scripted.Code != serverapi.CodeSuccess {
+			resp.Code, resp.Message = scripted.Code, scripted.Message
+			return http.StatusOK, resp
+		}
+	}
+
+	resp.Data.Results = make([]*server.ClusterAgentResult, 0)
+
+	if isListAgent(request.Path) {
+		return http.StatusOK, resp
+	}
+
+	// every agent is in the results as the real server does, the agents not scripted succeed.
+	for _, agentID := range request.AgentIDList {
+		result := AgentResult{Code: serverapi.CodeSuccess, Message: "success"}
+
+		if results := s.agentResults[agentID]; len(results) != 0 {
+			result = results[0]
+			s.agentResults[agentID] = results[1:]
+		}
+
+		resp.Data.Results = append(resp.Data.Results, &server.ClusterAgentResult{
+			AgentID: agentID,
+			Code:    result.Code,
+			Message: result.Message,
+		})
+	}
+
+	return http.StatusOK, resp
+}
+
+func isListAgent(path string) bool {
+	return path == pathListAgentState || path == pathListAgentInfo
+}
+
+// authorizedAPIGw validates the api gateway auth header, it's called with lock.
+func (s *Server) authorizedAPIGw(header http.Header) bool {
+	if s.conf.AppCode == "" {
+		return true
+	}
+
+	auth := struct {
+		AppCode   string `json:"bk_app_code"`
+		AppSecret string `json:"bk_app_secret"`
+	}{}
+
+	if err := json.Unmarshal([]byte(header.Get(headerAPIGwAuthKey)), &auth); err != nil {
+		return false
+	}
+
+	return auth.AppCode == s.conf.AppCode && auth.AppSecret == s.conf.AppSecret
+}
+
+// reply fires the callbacks of Reply for the agents succeeded in dispatching.
+func (s *Server) reply(request *Request, resp *server.ClusterDispatchMessageResp) {
+	if s.conf.Reply == nil {
+		return
+	}
+
+	failed := make(map[string]struct{}, len(resp.Data.Results))
+	for _, result := range resp.Data.Results {
+		if result.Code != serverapi.CodeSuccess {
+			failed[result.AgentID] = struct{}{}
+		}
+	}
+
+	for _, agentID := range request.AgentIDList {
+		if _, ok := failed[agentID]; ok {
+			continue
+		}
+
+		content, ok := s.conf.Reply(request.MessageID, agentID, request.Contents[agentID])
+		if !ok {
+			continue
+		}
+
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			return
+		}
+
+		s.replies.Add(1)
+		s.mutex.Unlock()
+
+		go func(agentID string) {
+			defer s.replies.Done()
+
+			if err := s.Respond(context.Background(), request.MessageID, agentID, content); err != nil {
+				s.mutex.Lock()
+				s.replyErrors = append(s.replyErrors, err)
+				s.mutex.Unlock()
+			}
+		}(agentID)
+	}
+}
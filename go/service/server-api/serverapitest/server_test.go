@@ -0,0 +1,306 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapitest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	serverapi "github.com/TencentBlueKing/bk-gse-sdk/go/service/server-api"
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+const (
+	testSlotID    = 1
+	testToken     = "token"
+	testAppCode   = "app"
+	testAppSecret = "secret"
+
+	// codeAgentOffline is a failure code scripted to the agents.
+	codeAgentOffline = 1001001
+)
+
+// newTestCluster creates a cluster client of the fake server with the auth.
+func newTestCluster(t *testing.T, fake *Server, slotID int, token, appSecret string) serverapi.Cluster {
+	t.Helper()
+
+	client, err := serverapi.New(
+		serverapi.WithBaseURL(fake.URL),
+		serverapi.WithClusterAuth(slotID, token),
+		serverapi.WithAPIGwAuth(testAppCode, appSecret),
+		serverapi.WithErrorCode(CodeAuthFailed, serverapi.ErrorCode{Err: types.ErrNotAthorized()}),
+		serverapi.WithErrorCode(codeAgentOffline, serverapi.ErrorCode{Err: types.ErrAgentOffline()}),
+		serverapi.WithLogger(types.NewEmptyLogger()),
+	)
+	if err != nil {
+		t.Fatalf("new client failed: %v", err)
+	}
+
+	return client.Cluster()
+}
+
+// resultCodes returns the codes of agent results by agent id.
+func resultCodes(resp *serverapi.ClusterPluginDispatchMessageResp) map[string]int {
+	codes := make(map[string]int, len(resp.AgentResults))
+	for agentID, result := range resp.AgentResults {
+		codes[agentID] = result.Code
+	}
+
+	return codes
+}
+
+func TestServerDispatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		appSecret string
+		responses []Response
+		agents    map[string][]AgentResult
+		codes     []map[string]int
+		errs      []error
+	}{
+		{
+			name:  "success",
+			codes: []map[string]int{{"a": 0, "b": 0}},
+			errs:  []error{nil},
+		},
+		{
+			name:   "scripted agents",
+			agents: map[string][]AgentResult{"b": {{Code: codeAgentOffline, Message: "offline"}}},
+			codes:  []map[string]int{{"a": 0, "b": codeAgentOffline}, {"a": 0, "b": 0}},
+			errs:   []error{nil, nil},
+		},
+		{
+			name: "scripted responses",
+			responses: []Response{
+				{StatusCode: http.StatusServiceUnavailable},
+				{Code: codeAgentOffline, Message: "offline"},
+			},
+			codes: []map[string]int{nil, nil, {"a": 0, "b": 0}},
+			errs: []error{
+				&types.HTTPStatusError{StatusCode: http.StatusServiceUnavailable},
+				types.ErrAgentOffline(),
+				nil,
+			},
+		},
+		{
+			name:  "invalid token",
+			token: "invalid",
+			codes: []map[string]int{nil},
+			errs:  []error{types.ErrNotAthorized()},
+		},
+		{
+			name:      "invalid api gateway auth",
+			appSecret: "invalid",
+			codes:     []map[string]int{nil},
+			errs:      []error{&types.HTTPStatusError{StatusCode: http.StatusUnauthorized}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewServer(WithClusterAuth(testSlotID, testToken), WithAPIGwAuth(testAppCode, testAppSecret))
+			defer fake.Close()
+
+			fake.ScriptResponses(tt.responses...)
+			for agentID, results := range tt.agents {
+				fake.ScriptAgent(agentID, results...)
+			}
+
+			token, appSecret := testToken, testAppSecret
+			if tt.token != "" {
+				token = tt.token
+			}
+
+			if tt.appSecret != "" {
+				appSecret = tt.appSecret
+			}
+
+			cluster := newTestCluster(t, fake, testSlotID, token, appSecret)
+
+			for i := range tt.codes {
+				resp, err := cluster.PluginDispatchMessage(context.Background(), "message", []byte("ping"), "a", "b")
+				if err == nil {
+					err = resp.Err()
+				}
+
+				if !matchError(err, tt.errs[i]) {
+					t.Fatalf("dispatch %d returns %v, want %v", i, err, tt.errs[i])
+				}
+
+				if tt.codes[i] == nil {
+					continue
+				}
+
+				if codes := resultCodes(resp); !reflect.DeepEqual(codes, tt.codes[i]) {
+					t.Fatalf("dispatch %d returns codes %v, want %v", i, codes, tt.codes[i])
+				}
+			}
+
+			if requests := fake.Requests(); len(requests) != len(tt.codes) {
+				t.Fatalf("%d requests recorded, want %d", len(requests), len(tt.codes))
+			}
+		})
+	}
+}
+
+// matchError returns true if err matches want, the http status errors are matched by status code.
+func matchError(err, want error) bool {
+	var wantStatus *types.HTTPStatusError
+	if errors.As(want, &wantStatus) {
+		var statusErr *types.HTTPStatusError
+		return errors.As(err, &statusErr) && statusErr.StatusCode == wantStatus.StatusCode
+	}
+
+	if want == nil {
+		return err == nil
+	}
+
+	return errors.Is(err, want)
+}
+
+func TestServerRequests(t *testing.T) {
+	fake := NewServer(WithClusterAuth(testSlotID, testToken))
+	defer fake.Close()
+
+	cluster := newTestCluster(t, fake, testSlotID, testToken, "")
+
+	if _, err := cluster.PluginDispatchMessage(context.Background(), "m1", []byte("ping"), "a", "b"); err != nil {
+		t.Fatalf("dispatch message failed: %v", err)
+	}
+
+	multi := &serverapi.ClusterPluginDispatchMultiMessageReq{
+		MessageID: "m2",
+		Messages: []*serverapi.AgentMessage{
+			{AgentID: "a", Content: []byte("1")},
+			{AgentID: "b", Content: []byte("2")},
+		},
+		FrontContent: []byte("["),
+		BackContent:  []byte("]"),
+	}
+
+	if _, err := cluster.PluginDispatchMultiMessage(context.Background(), multi); err != nil {
+		t.Fatalf("dispatch multi message failed: %v", err)
+	}
+
+	want := []*Request{
+		{
+			Path:        pathDispatchMessage,
+			SlotID:      testSlotID,
+			Token:       testToken,
+			MessageID:   "m1",
+			AgentIDList: []string{"a", "b"},
+			Contents:    map[string]string{"a": "ping", "b": "ping"},
+		},
+		{
+			Path:        pathDispatchMultiMessage,
+			SlotID:      testSlotID,
+			Token:       testToken,
+			MessageID:   "m2",
+			AgentIDList: []string{"a", "b"},
+			Contents:    map[string]string{"a": "[1]", "b": "[2]"},
+		},
+	}
+
+	requests := fake.Requests()
+	for _, request := range requests {
+		request.Header = nil
+	}
+
+	if !reflect.DeepEqual(requests, want) {
+		t.Fatalf("recorded requests %+v, want %+v", requests, want)
+	}
+
+	fake.Reset()
+
+	if requests := fake.Requests(); len(requests) != 0 {
+		t.Fatalf("%d requests recorded after reset, want 0", len(requests))
+	}
+}
+
+func TestServerReply(t *testing.T) {
+	fake := NewServer(
+		WithClusterAuth(testSlotID, testToken),
+		WithReply(func(_, agentID, content string) ([]byte, bool) {
+			return []byte(content + "-" + agentID), agentID != "c"
+		}),
+	)
+
+	var answers []string
+	var mutex sync.Mutex
+
+	handler, err := serverapi.NewCallbackHandler(newTestCluster(t, fake, testSlotID, testToken, ""),
+		serverapi.WithCallbackLogger(types.NewEmptyLogger()))
+	if err != nil {
+		t.Fatalf("new callback handler failed: %v", err)
+	}
+
+	handler.HandleDefault(func(_ context.Context, message *serverapi.ClusterPluginRespondMessage) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		answers = append(answers, message.MessageID+":"+message.Content)
+
+		return nil
+	})
+
+	callback := httptest.NewServer(handler)
+	defer callback.Close()
+
+	fake.SetCallbackURL(callback.URL)
+	fake.ScriptAgent("b", AgentResult{Code: codeAgentOffline})
+
+	cluster := newTestCluster(t, fake, testSlotID, testToken, "")
+	if _, err := cluster.PluginDispatchMessage(context.Background(), "m1", []byte("ping"), "a", "b", "c"); err != nil {
+		t.Fatalf("dispatch message failed: %v", err)
+	}
+
+	if err := fake.Respond(context.Background(), "m2", "d", []byte("hello")); err != nil {
+		t.Fatalf("respond failed: %v", err)
+	}
+
+	// close waits for the replies in flight.
+	fake.Close()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	sort.Strings(answers)
+
+	// only the agents succeeded in dispatching and answered by Reply reply.
+	if want := []string{"m1:ping-a", "m2:hello"}; !reflect.DeepEqual(answers, want) {
+		t.Fatalf("handled answers %v, want %v", answers, want)
+	}
+
+	if errs := fake.ReplyErrors(); len(errs) != 0 {
+		t.Fatalf("reply errors %v, want none", errs)
+	}
+}
+
+func TestServerRespondWithoutCallbackURL(t *testing.T) {
+	fake := NewServer()
+	defer fake.Close()
+
+	if err := fake.Respond(context.Background(), "message", "a", []byte("hello")); err == nil {
+		t.Fatal("respond succeeded without callback url")
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package serverapi
+
+import (
+	"context"
+
+	"github.com/TencentBlueKing/bk-gse-sdk/go/types"
+)
+
+// DispatchTyped encodes the value with the cluster codec and dispatches it to agents.
+// the encoding failure returns *types.CodecError.
+func DispatchTyped[T any](ctx context.Context, cluster Cluster, messageID string, value T, agentIDList ...string) (
+	*ClusterPluginDispatchMessageResp, error) {
+
+	codec := cluster.Codec()
+
+	content, err := codec.Marshal(value)
+	if err != nil {
+		return nil, &types.CodecError{
+			Codec:     codec.Name(),
+			Operation: types.CodecOperationEncode,
+			MessageID: messageID,
+			Err:       err,
+		}
+	}
+
+	return cluster.PluginDispatchMessage(ctx, messageID, content, agentIDList...)
+}
+
+// DecodeRespond decodes the content of respond message into T with the cluster codec.
+// the decoding failure returns *types.CodecError.
+func DecodeRespond[T any](cluster Cluster, message *ClusterPluginRespondMessage) (T, error) {
+	codec := cluster.Codec()
+
+	var value T
+	if err := codec.Unmarshal([]byte(message.Content), &value); err != nil {
+		return value, &types.CodecError{
+			Codec:     codec.Name(),
+			Operation: types.CodecOperationDecode,
+			MessageID: message.MessageID,
+			Err:       err,
+		}
+	}
+
+	return value, nil
+}
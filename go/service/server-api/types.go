@@ -22,6 +22,27 @@ type ClusterPluginDispatchMessageResp struct {
 	Code         int
 	Message      string
 	AgentResults map[string]*types.DispatchAgentResult
+
+	// errorCodes classifies the codes, it's Config.ErrorCodes of the client created it.
+	errorCodes map[int]ErrorCode
+}
+
+// ClusterPluginDispatchMultiMessageReq describes the request of Cluster DispatchMultiMessage.
+type ClusterPluginDispatchMultiMessageReq struct {
+	MessageID string
+
+	// Messages describes the content of every agent.
+	Messages []*AgentMessage
+
+	// FrontContent and BackContent describe the common content before and after the content of every agent.
+	FrontContent []byte
+	BackContent  []byte
+}
+
+// AgentMessage describes the message content of an agent.
+type AgentMessage struct {
+	AgentID string
+	Content []byte
 }
 
 // ClusterPluginRespondMessage describes the body of respond message from agent.
@@ -0,0 +1,93 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Codec defines the encoder/decoder of typed message content.
+type Codec interface {
+	// Name returns the name of codec.
+	Name() string
+
+	// Marshal encodes the value into bytes.
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal decodes the bytes into the value.
+	Unmarshal(data []byte, v any) error
+}
+
+// NewJSONCodec creates a new JSONCodec.
+func NewJSONCodec() Codec {
+	return &JSONCodec{}
+}
+
+// JSONCodec encodes/decodes message content in json.
+type JSONCodec struct {
+}
+
+// Name returns the name of codec.
+func (c JSONCodec) Name() string {
+	return "json"
+}
+
+// Marshal encodes the value into json.
+func (c JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes the json into the value.
+func (c JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// CodecOperation describes the operation of codec.
+type CodecOperation string
+
+const (
+	// CodecOperationEncode means encoding the value into message content.
+	CodecOperationEncode CodecOperation = "encode"
+
+	// CodecOperationDecode means decoding the message content into value.
+	CodecOperationDecode CodecOperation = "decode"
+)
+
+// CodecError describes the error when codec failed to encode or decode a message.
+type CodecError struct {
+	// Codec is the name of codec.
+	Codec string
+
+	// Operation is the failed operation.
+	Operation CodecOperation
+
+	// MessageID is the id of the message.
+	MessageID string
+
+	// Err is the underlying error from codec.
+	Err error
+}
+
+// Error returns the error message.
+func (e *CodecError) Error() string {
+	return fmt.Sprintf("%s codec %s message %s failed: %v", e.Codec, e.Operation, e.MessageID, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *CodecError) Unwrap() error {
+	return e.Err
+}
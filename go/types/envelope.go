@@ -0,0 +1,54 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package types
+
+import "time"
+
+// EnvelopeKey describes a key shared between server and plugin for message envelope.
+type EnvelopeKey struct {
+	// ID identifies the key, it's carried in every envelope for the receiver to pick the same key.
+	ID string
+
+	// SignKey is the HMAC-SHA256 signing key.
+	SignKey []byte
+
+	// EncryptKey is the AES-GCM encryption key, which must be 16, 24 or 32 bytes.
+	// it could be empty if the key is never used to encrypt.
+	EncryptKey []byte
+}
+
+// EnvelopeConfig describes the message envelope which signs and optionally encrypts the message content.
+// once enabled, the incoming messages without a valid envelope will be rejected.
+type EnvelopeConfig struct {
+	// KeyID is the id of the key to seal outgoing messages, empty means the envelope is disabled.
+	KeyID string
+
+	// Keys are all the keys to open incoming messages, it must contain the key of KeyID.
+	// keeping the old keys here makes key rotation possible.
+	Keys []EnvelopeKey
+
+	// Encrypt describes whether to encrypt the outgoing messages.
+	Encrypt bool
+
+	// ReplayWindow describes how far the sealed time of an incoming message could be away from now,
+	// and how long the seen nonces are kept to reject replayed messages.
+	ReplayWindow time.Duration
+}
+
+// Enabled returns true if the envelope is enabled.
+func (c EnvelopeConfig) Enabled() bool {
+	return c.KeyID != ""
+}
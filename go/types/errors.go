@@ -15,7 +15,10 @@
 
 package types
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	errAlreadyLaunched   = errors.New("already launched")
@@ -26,6 +29,18 @@ var (
 	errInvalidProtocol   = errors.New("invalid protocol")
 	errNotAthorized      = errors.New("not authorized")
 	errInvalidConfig     = errors.New("invalid config")
+	errInvalidChunk      = errors.New("invalid chunk")
+	errChunkIncomplete   = errors.New("chunked message incomplete")
+	errEnvelopeRejected  = errors.New("envelope rejected")
+	errMessageTooLarge   = errors.New("message too large")
+	errRateLimited       = errors.New("rate limited")
+	errInvalidTimestamp  = errors.New("invalid timestamp")
+	errNotSynced         = errors.New("not yet synced")
+	errAgentUnavailable  = errors.New("agent unavailable")
+	errQueueFull         = errors.New("queue full")
+	errSlotNotFound      = errors.New("slot not found")
+	errAgentOffline      = errors.New("agent offline")
+	errPluginNotConnect  = errors.New("plugin not connected")
 )
 
 // ErrAlreadyLaunched defines the error when client already launched.
@@ -67,3 +82,145 @@ func ErrNotAthorized() error {
 func ErrInvalidConfig() error {
 	return errInvalidConfig
 }
+
+// ErrInvalidChunk defines the error when a chunk of chunked message invalid.
+func ErrInvalidChunk() error {
+	return errInvalidChunk
+}
+
+// ErrChunkIncomplete defines the error when a chunked message is still waiting for the rest chunks.
+func ErrChunkIncomplete() error {
+	return errChunkIncomplete
+}
+
+// ErrEnvelopeRejected defines the error when a message failed in envelope verification.
+func ErrEnvelopeRejected() error {
+	return errEnvelopeRejected
+}
+
+// ErrMessageTooLarge defines the error when a message is larger than the limit.
+func ErrMessageTooLarge() error {
+	return errMessageTooLarge
+}
+
+// ErrRateLimited defines the error when a message is dropped by rate limit.
+func ErrRateLimited() error {
+	return errRateLimited
+}
+
+// ErrInvalidTimestamp defines the error when a timestamp is out of the valid range.
+func ErrInvalidTimestamp() error {
+	return errInvalidTimestamp
+}
+
+// ErrNotSynced defines the error when the config is not synced from agent yet.
+func ErrNotSynced() error {
+	return errNotSynced
+}
+
+// ErrAgentUnavailable defines the error when sending is deferred or rejected for the agent status.
+// the details are in *AgentStatusError.
+func ErrAgentUnavailable() error {
+	return errAgentUnavailable
+}
+
+// ErrQueueFull defines the error when a sending is not queued for the queue is full.
+func ErrQueueFull() error {
+	return errQueueFull
+}
+
+// ErrSlotNotFound defines the error when the plugin message slot is not found in server.
+func ErrSlotNotFound() error {
+	return errSlotNotFound
+}
+
+// ErrAgentOffline defines the error when the agent is offline in server.
+func ErrAgentOffline() error {
+	return errAgentOffline
+}
+
+// ErrPluginNotConnected defines the error when the plugin is not connected to the agent.
+func ErrPluginNotConnected() error {
+	return errPluginNotConnect
+}
+
+// AgentStatusError describes why a sending is deferred or rejected for the agent status.
+type AgentStatusError struct {
+	// Status is the agent status when the sending failed.
+	Status AgentStatus
+
+	// Err is the cause, types.ErrContextDone if the context is done while waiting,
+	// types.ErrQueueFull if the sending is not queued for the queue is full, otherwise nil.
+	Err error
+
+	// Queued is true if the sending is queued with AgentStatusPolicyQueue, it will be sent when the agent
+	// is running again, so the caller should not send it again.
+	Queued bool
+}
+
+// Error returns the error message.
+func (e *AgentStatusError) Error() string {
+	if e.Queued {
+		return fmt.Sprintf("agent unavailable in status %s, sending queued", e.Status.String())
+	}
+
+	if e.Err != nil {
+		return fmt.Sprintf("agent unavailable in status %s: %v", e.Status.String(), e.Err)
+	}
+
+	return fmt.Sprintf("agent unavailable in status %s", e.Status.String())
+}
+
+// Unwrap returns the errors it wraps, including ErrAgentUnavailable.
+func (e *AgentStatusError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{errAgentUnavailable, e.Err}
+	}
+
+	return []error{errAgentUnavailable}
+}
+
+// IsQueued returns true if err is the *AgentStatusError of a queued sending, which will be sent when
+// the agent is running again, so it should be treated as sent.
+func IsQueued(err error) bool {
+	var statusErr *AgentStatusError
+
+	return errors.As(err, &statusErr) && statusErr.Queued
+}
+
+// TerminateError describes the works abandoned when a client terminated before all works drained.
+type TerminateError struct {
+	// AbandonedHandlers is the number of message callbacks still running when terminated.
+	AbandonedHandlers int
+
+	// AbandonedSends is the number of sends still writing when terminated, they are aborted by closing connection.
+	AbandonedSends int
+
+	// Err is the error of context which stopped the draining.
+	Err error
+}
+
+// Error returns the error message.
+func (e *TerminateError) Error() string {
+	return fmt.Sprintf("terminated with %d handlers and %d sends abandoned: %v",
+		e.AbandonedHandlers, e.AbandonedSends, e.Err)
+}
+
+// Unwrap returns the error of context.
+func (e *TerminateError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatusError describes the failure of a request to server responded with a non-200 status code.
+type HTTPStatusError struct {
+	// URL is the url of the request.
+	URL string
+
+	// StatusCode is the http status code of the response.
+	StatusCode int
+}
+
+// Error returns the error message.
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("request to %s failed, status code: %d", e.URL, e.StatusCode)
+}
@@ -0,0 +1,146 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - 管控平台(BlueKing - General Service Engine) available.
+ * Copyright (C) 2025 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the specific
+ * language governing permissions and limitations under the License.We undertake not
+ * to change the open source license (MIT license) applicable to the current version
+ * of the project delivered to anyone in the future.
+ */
+
+package types
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// Stats describes the delivery statistics of a client.
+// all counters are cumulative since the client is created, diff two snapshots to get the rate in a period.
+type Stats struct {
+	// Send describes the frames sent to agent, including keepalive.
+	Send DirectionStats
+
+	// Recv describes the frames received from agent.
+	Recv DirectionStats
+
+	// DataIDs describes the reports sent to agent by data-id, it's empty for the clients without data-id.
+	DataIDs map[uint32]DirectionStats
+
+	// Reconnects is the number of times the connection is re-established after lost.
+	Reconnects uint64
+
+	// KeepaliveRTT is the round-trip time of the latest keepalive, 0 if no response yet.
+	KeepaliveRTT time.Duration
+}
+
+// DirectionStats describes the statistics of frames in one direction.
+type DirectionStats struct {
+	// Frames is the number of frames succeed.
+	Frames uint64
+
+	// Bytes is the size in bytes of frames succeed, including the protocol header.
+	Bytes uint64
+
+	// Errors is the number of failures by kind.
+	Errors map[ErrorKind]uint64
+
+	// LastSuccessAt is the time of the latest frame succeed, zero if none.
+	LastSuccessAt time.Time
+}
+
+// ErrorKind describes the kind of errors in statistics.
+type ErrorKind string
+
+const (
+	// ErrorKindContextDone means the context is done or canceled.
+	ErrorKindContextDone ErrorKind = "context_done"
+
+	// ErrorKindNotLaunched means the client is not launched or terminated.
+	ErrorKindNotLaunched ErrorKind = "not_launched"
+
+	// ErrorKindNotConnected means the client is not connected to agent.
+	ErrorKindNotConnected ErrorKind = "not_connected"
+
+	// ErrorKindNotAuthorized means the client is not authorized by agent.
+	ErrorKindNotAuthorized ErrorKind = "not_authorized"
+
+	// ErrorKindNotSynced means the config is not synced from agent.
+	ErrorKindNotSynced ErrorKind = "not_synced"
+
+	// ErrorKindRateLimited means the report is over rate limits.
+	ErrorKindRateLimited ErrorKind = "rate_limited"
+
+	// ErrorKindAgentUnavailable means the agent is unavailable in its status.
+	ErrorKindAgentUnavailable ErrorKind = "agent_unavailable"
+
+	// ErrorKindMessageTooLarge means the message is over the size limit.
+	ErrorKindMessageTooLarge ErrorKind = "message_too_large"
+
+	// ErrorKindInvalidTimestamp means the report time is out of the valid range.
+	ErrorKindInvalidTimestamp ErrorKind = "invalid_timestamp"
+
+	// ErrorKindInvalidProtocol means the frame violates the protocol.
+	ErrorKindInvalidProtocol ErrorKind = "invalid_protocol"
+
+	// ErrorKindInvalidChunk means the chunk of chunked message is invalid.
+	ErrorKindInvalidChunk ErrorKind = "invalid_chunk"
+
+	// ErrorKindEnvelope means the envelope of message is rejected.
+	ErrorKindEnvelope ErrorKind = "envelope_rejected"
+
+	// ErrorKindCodec means the typed message failed in codec.
+	ErrorKindCodec ErrorKind = "codec"
+
+	// ErrorKindNetwork means the connection failed.
+	ErrorKindNetwork ErrorKind = "network"
+
+	// ErrorKindOther means the errors of other kinds.
+	ErrorKindOther ErrorKind = "other"
+)
+
+// ErrorKindOf returns the kind of err.
+func ErrorKindOf(err error) ErrorKind {
+	var codecErr *CodecError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, errContextDone), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindContextDone
+	case errors.Is(err, errAgentUnavailable):
+		return ErrorKindAgentUnavailable
+	case errors.Is(err, errNotLaunched), errors.Is(err, errAlreadyTerminated):
+		return ErrorKindNotLaunched
+	case errors.Is(err, errNotConnected):
+		return ErrorKindNotConnected
+	case errors.Is(err, errNotAthorized):
+		return ErrorKindNotAuthorized
+	case errors.Is(err, errNotSynced):
+		return ErrorKindNotSynced
+	case errors.Is(err, errRateLimited):
+		return ErrorKindRateLimited
+	case errors.Is(err, errMessageTooLarge):
+		return ErrorKindMessageTooLarge
+	case errors.Is(err, errInvalidTimestamp):
+		return ErrorKindInvalidTimestamp
+	case errors.Is(err, errInvalidProtocol):
+		return ErrorKindInvalidProtocol
+	case errors.Is(err, errInvalidChunk):
+		return ErrorKindInvalidChunk
+	case errors.Is(err, errEnvelopeRejected):
+		return ErrorKindEnvelope
+	case errors.As(err, &codecErr):
+		return ErrorKindCodec
+	case errors.As(err, &netErr):
+		return ErrorKindNetwork
+	default:
+		return ErrorKindOther
+	}
+}
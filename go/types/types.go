@@ -16,6 +16,8 @@
 // Package types provides the types and constants used throughout the sdk.
 package types
 
+import "fmt"
+
 // AgentStatus describes the agent status.
 type AgentStatus int
 
@@ -48,12 +50,71 @@ const (
 	AgentStatusUninit AgentStatus = 7
 )
 
+// String returns the name of status.
+func (s AgentStatus) String() string {
+	switch s {
+	case AgentStatusUnknown:
+		return "unknown"
+	case AgentStatusInit:
+		return "init"
+	case AgentStatusStarting:
+		return "starting"
+	case AgentStatusRunning:
+		return "running"
+	case AgentStatusDamage:
+		return "damage"
+	case AgentStatusBusy:
+		return "busy"
+	case AgentStatusUpgrading:
+		return "upgrading"
+	case AgentStatusStopping:
+		return "stopping"
+	case AgentStatusUninit:
+		return "uninit"
+	default:
+		return fmt.Sprintf("status(%d)", int(s))
+	}
+}
+
+// AgentStatusPolicy describes how to send while the agent is not available.
+type AgentStatusPolicy int
+
+const (
+	// AgentStatusPolicyIgnore sends regardless of the agent status.
+	AgentStatusPolicyIgnore AgentStatusPolicy = 0
+
+	// AgentStatusPolicyWait pauses the sending while the agent is busy, upgrading or stopping until it's running
+	// again or the context is done, and fails fast while the agent is damage.
+	AgentStatusPolicyWait AgentStatusPolicy = 1
+
+	// AgentStatusPolicyReject fails fast while the agent is busy, upgrading, stopping or damage.
+	AgentStatusPolicyReject AgentStatusPolicy = 2
+
+	// AgentStatusPolicyQueue queues the sending while the agent is busy, upgrading or stopping, and sends
+	// the queued ones in order when it's running again. it fails fast while the agent is damage or the queue
+	// is full.
+	AgentStatusPolicyQueue AgentStatusPolicy = 3
+)
+
 // AgentSimpleInfo describes the simple agent info.
 type AgentSimpleInfo struct {
 	CloudID int
 	AgentID string
 }
 
+// AgentState describes the agent state.
+type AgentState struct {
+	AgentSimpleInfo
+
+	StatusCode AgentStatus
+	Status     string
+}
+
+// IsRunning returns true if the agent is running.
+func (state *AgentState) IsRunning() bool {
+	return state.StatusCode == AgentStatusRunning
+}
+
 // AgentInfo describes the agent info.
 type AgentInfo struct {
 	AgentSimpleInfo
@@ -80,3 +141,46 @@ type DispatchAgentResult struct {
 	Code    int
 	Message string
 }
+
+// ClientState describes the lifecycle state of an agent side client.
+type ClientState int
+
+const (
+	// ClientStateIdle means the client is created but never launched.
+	ClientStateIdle ClientState = 0
+
+	// ClientStateConnecting means the client is launched and connecting to agent.
+	ClientStateConnecting ClientState = 1
+
+	// ClientStateConnected means the client is connected to agent.
+	ClientStateConnected ClientState = 2
+
+	// ClientStateAuthorized means the client is connected and got the response of keepalive from agent.
+	ClientStateAuthorized ClientState = 3
+
+	// ClientStateDraining means the client is terminating and waiting for the in-flight works.
+	ClientStateDraining ClientState = 4
+
+	// ClientStateStopped means the client is terminated, it could be launched again.
+	ClientStateStopped ClientState = 5
+)
+
+// String returns the name of state.
+func (s ClientState) String() string {
+	switch s {
+	case ClientStateIdle:
+		return "idle"
+	case ClientStateConnecting:
+		return "connecting"
+	case ClientStateConnected:
+		return "connected"
+	case ClientStateAuthorized:
+		return "authorized"
+	case ClientStateDraining:
+		return "draining"
+	case ClientStateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}